@@ -22,10 +22,18 @@
 package golicenser
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
 	"golang.org/x/sync/errgroup"
@@ -35,6 +43,10 @@ import (
 const (
 	analyzerName = "golicenser"
 
+	// CategoryMigrate is the diagnostic category of license headers that
+	// require migration.
+	CategoryMigrate = "migrate"
+
 	// DefaultCopyrightHeaderMatcher is the default regexp used to detect the
 	// existence of any copyright header. This will match any header containing
 	// "copyright".
@@ -43,11 +55,82 @@ const (
 
 // Config is the golicenser configuration.
 type Config struct {
-	Header HeaderOpts
+	Header HeaderOpts `json:"header"`
+
+	// Rules are license header rules for specific paths. The header of the
+	// first rule with a matching path is used, otherwise Header is used.
+	Rules []Rule `json:"rules,omitempty"`
+
+	Exclude                []string `json:"exclude,omitempty"`
+	MaxConcurrent          int      `json:"max-concurrent,omitempty"`
+	CopyrightHeaderMatcher string   `json:"copyright-header-matcher,omitempty"`
+
+	// Strict reports copyright headers that are not recognised by the
+	// license header, such as a header with a different copyright holder or
+	// a mangled license header. By default, these headers are left as-is.
+	Strict bool `json:"strict,omitempty"`
+
+	// History provides the file history used by year modes, for headers that
+	// do not set their own. If nil, the history is created from HistorySource
+	// and HistoryFile.
+	History History `json:"-"`
+
+	// HistorySource is the history source used if History is nil: "git"
+	// (default), "fs" or "json". See NewHistory.
+	HistorySource string `json:"history,omitempty"`
+
+	// HistoryFile is the JSON history file used by the "json" history source.
+	HistoryFile string `json:"history-file,omitempty"`
+
+	// GitDate is the commit date used by the "git" history source:
+	// "committer" (default) or "author".
+	GitDate string `json:"git-date,omitempty"`
+
+	// IgnoreRevsFile is a .git-blame-ignore-revs style file of commits that
+	// are not counted as modifications by the "git" history source.
+	IgnoreRevsFile string `json:"ignore-revs-file,omitempty"`
+
+	// IgnoreRevs are commits that are not counted as modifications by the
+	// "git" history source.
+	IgnoreRevs []string `json:"ignore-revs,omitempty"`
+
+	// IgnoreMessages are regexps matching the subjects of commits that are not
+	// counted as modifications by the "git" history source.
+	IgnoreMessages []string `json:"ignore-messages,omitempty"`
 
-	Exclude                []string
-	MaxConcurrent          int
-	CopyrightHeaderMatcher string
+	// Clock returns the current time, for headers that do not set their own.
+	// If nil, the wall clock is used.
+	Clock func() time.Time `json:"-"`
+
+	// Since limits year updates to files changed since the merge base of a
+	// Git ref, such as "origin/main", including uncommitted and untracked
+	// files. The license headers of other files are kept as-is, and only
+	// missing or mismatched license headers are reported for them.
+	Since string `json:"since,omitempty"`
+
+	// Staged limits year updates to files with staged changes. If Since is
+	// also set, files changed in either are updated.
+	Staged bool `json:"staged,omitempty"`
+
+	// MaxProcesses is the maximum number of concurrent external processes,
+	// such as git, started by golicenser. Unlike MaxConcurrent, the limit is
+	// process-wide and shared between all analyzers and linters. If zero, the
+	// current limit (by default GOMAXPROCS) is kept.
+	MaxProcesses int `json:"max-processes,omitempty"`
+
+	// CacheDir is the directory used by a Linter to cache check results. If
+	// empty, results are not cached. Results are keyed by the file content,
+	// the configuration, the current year and the file history.
+	CacheDir string `json:"-"`
+}
+
+// Rule is a license header used for files matching a set of paths.
+type Rule struct {
+	// Paths are the doublestar or r!-prefixed regexp patterns to match.
+	Paths []string `json:"paths"`
+
+	// Header is the license header to use for matching files.
+	Header HeaderOpts `json:"header"`
 }
 
 // NewAnalyzer creates a golicenser analyzer.
@@ -75,6 +158,17 @@ type analyzer struct {
 	headerMatcher *regexp.Regexp
 
 	header *Header
+	rules  []rule
+
+	// changes are the files changed in Git, or nil if year updates are not
+	// limited to changed files.
+	changes *gitChanges
+}
+
+// rule is a compiled Rule.
+type rule struct {
+	paths  []func(filename string) bool
+	header *Header
 }
 
 func newAnalyzer(cfg Config) (*analyzer, error) {
@@ -82,7 +176,10 @@ func newAnalyzer(cfg Config) (*analyzer, error) {
 		cfg.CopyrightHeaderMatcher = DefaultCopyrightHeaderMatcher
 	}
 
-	a := &analyzer{cfg: cfg}
+	a := &analyzer{cfg: cfg, changes: newGitChanges(cfg.Since, cfg.Staged)}
+	if cfg.MaxProcesses > 0 {
+		processes.setLimit(cfg.MaxProcesses)
+	}
 
 	var err error
 	a.headerMatcher, err = regexp.Compile(a.cfg.CopyrightHeaderMatcher)
@@ -95,27 +192,36 @@ func newAnalyzer(cfg Config) (*analyzer, error) {
 		if exclude == "" {
 			continue
 		}
-
-		if strings.HasPrefix(exclude, "r!") {
-			expr := strings.TrimPrefix(exclude, "r!")
-			re, err := regexp.Compile(expr)
-			if err != nil {
-				return nil, fmt.Errorf("invalid exclude regexp pattern (%s): %w",
-					expr, err)
-			}
-			a.excludes = append(a.excludes, func(filename string) bool {
-				return re.MatchString(filename)
-			})
-			continue
+		match, err := compilePattern(exclude)
+		if err != nil {
+			return nil, fmt.Errorf("exclude: %w", err)
 		}
+		a.excludes = append(a.excludes, match)
+	}
 
-		if !doublestar.ValidatePattern(exclude) {
-			return nil, fmt.Errorf("invalid exclude pattern: %s", exclude)
-		}
-		a.excludes = append(a.excludes, func(filename string) bool {
-			matched, _ := doublestar.Match(exclude, filename)
-			return matched
+	// Create history provider.
+	if cfg.History == nil {
+		cfg.History, err = NewHistory(HistoryOpts{
+			Source: cfg.HistorySource,
+			File:   cfg.HistoryFile,
+			Git: GitHistoryOpts{
+				Date:           cfg.GitDate,
+				IgnoreRevsFile: cfg.IgnoreRevsFile,
+				IgnoreRevs:     cfg.IgnoreRevs,
+				IgnoreMessages: cfg.IgnoreMessages,
+			},
 		})
+		if err != nil {
+			return nil, fmt.Errorf("history: %w", err)
+		}
+	}
+	// Share history lookups between passes.
+	cfg.History = newMemoHistory(cfg.History)
+	if cfg.Header.History == nil {
+		cfg.Header.History = cfg.History
+	}
+	if cfg.Header.Clock == nil {
+		cfg.Header.Clock = cfg.Clock
 	}
 
 	// Create license header.
@@ -123,101 +229,382 @@ func newAnalyzer(cfg Config) (*analyzer, error) {
 	if err != nil {
 		return nil, err
 	}
+	if !cfg.Header.CommentStyle.isGo() {
+		return nil, fmt.Errorf("comment style %s cannot be used for Go files",
+			cfg.Header.CommentStyle)
+	}
+
+	// Compile rules.
+	for i, r := range cfg.Rules {
+		if len(r.Paths) == 0 {
+			return nil, fmt.Errorf("rule %d: no paths", i)
+		}
+		var cr rule
+		for _, p := range r.Paths {
+			match, err := compilePattern(p)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i, err)
+			}
+			cr.paths = append(cr.paths, match)
+		}
+		if r.Header.History == nil {
+			r.Header.History = cfg.History
+		}
+		if r.Header.Clock == nil {
+			r.Header.Clock = cfg.Clock
+		}
+		if cr.header, err = NewHeader(r.Header); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		if !r.Header.CommentStyle.isGo() {
+			return nil, fmt.Errorf("rule %d: comment style %s cannot be used for Go files",
+				i, r.Header.CommentStyle)
+		}
+		a.rules = append(a.rules, cr)
+	}
 
 	return a, nil
 }
 
-func (a *analyzer) run(pass *analysis.Pass) (any, error) {
-	if a.cfg.MaxConcurrent > 1 {
-		// Process files concurrently.
-		var errg errgroup.Group
-		errg.SetLimit(a.cfg.MaxConcurrent)
+// compilePattern compiles a doublestar or r!-prefixed regexp pattern into a
+// function matching filenames.
+func compilePattern(pattern string) (func(filename string) bool, error) {
+	if strings.HasPrefix(pattern, "r!") {
+		expr := strings.TrimPrefix(pattern, "r!")
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regexp pattern (%s): %w", expr, err)
+		}
+		return re.MatchString, nil
+	}
 
-		for _, file := range pass.Files {
-			if ast.IsGenerated(file) {
-				// Skip generated files.
-				continue
+	if !doublestar.ValidatePattern(pattern) {
+		return nil, fmt.Errorf("invalid pattern: %s", pattern)
+	}
+	return func(filename string) bool {
+		matched, _ := doublestar.Match(pattern, filename)
+		return matched
+	}, nil
+}
+
+// headerFor returns the license header to use for a file.
+func (a *analyzer) headerFor(filename string) *Header {
+	for _, r := range a.rules {
+		for _, match := range r.paths {
+			if match(filename) {
+				return r.header
 			}
+		}
+	}
+	return a.header
+}
 
-			errg.Go(func() error {
-				return a.checkFile(pass, file)
-			})
+func (a *analyzer) run(pass *analysis.Pass) (any, error) {
+	report := pass.Report
+	if a.cfg.MaxConcurrent > 1 {
+		// Diagnostics are reported from multiple goroutines.
+		var mu sync.Mutex
+		report = func(d analysis.Diagnostic) {
+			mu.Lock()
+			defer mu.Unlock()
+			pass.Report(d)
 		}
-		return nil, errg.Wait()
 	}
 
-	// Run without concurrency.
+	type check struct {
+		filename string
+		fn       func() error
+	}
+	var checks []check
 	for _, file := range pass.Files {
 		if ast.IsGenerated(file) {
 			// Skip generated files.
 			continue
 		}
+		checks = append(checks, check{
+			filename: pass.Fset.File(file.Pos()).Name(),
+			fn: func() error {
+				return a.checkFile(pass.Fset, report, file)
+			},
+		})
+	}
 
-		if err := a.checkFile(pass, file); err != nil {
-			return nil, fmt.Errorf("check %s: %w",
-				pass.Fset.File(file.Pos()).Name(), err)
+	// Non-Go files (e.g. cgo and assembly files), and Go files excluded by
+	// build constraints.
+	otherFiles := slices.Concat(pass.OtherFiles, pass.IgnoredFiles)
+	for _, filename := range otherFiles {
+		checks = append(checks, check{
+			filename: filename,
+			fn: func() error {
+				return a.checkOtherFile(pass, report, filename)
+			},
+		})
+	}
+
+	if a.cfg.MaxConcurrent > 1 {
+		// Process files concurrently.
+		var errg errgroup.Group
+		errg.SetLimit(a.cfg.MaxConcurrent)
+		for _, c := range checks {
+			errg.Go(c.fn)
+		}
+		return nil, errg.Wait()
+	}
+
+	// Run without concurrency.
+	for _, c := range checks {
+		if err := c.fn(); err != nil {
+			return nil, fmt.Errorf("check %s: %w", c.filename, err)
 		}
 	}
 
 	return nil, nil
 }
 
-func (a *analyzer) checkFile(pass *analysis.Pass, file *ast.File) error {
-	// Check whether the file is excluded.
-	filename := pass.Fset.File(file.Pos()).Name()
+// checkOtherFile checks the license header of a file that is not part of
+// pass.Files, such as cgo and assembly files, and Go files excluded by build
+// constraints.
+func (a *analyzer) checkOtherFile(pass *analysis.Pass, report func(analysis.Diagnostic), filename string) error {
+	if a.excluded(filename) {
+		return nil
+	}
+
+	var style CommentStyle
+	isGo := filepath.Ext(filename) == ".go"
+	if !isGo {
+		var ok bool
+		style, ok = commentStyleForFile(filename, a.headerFor(filename).commentStyle)
+		if !ok {
+			// Unsupported file type (e.g. .syso).
+			return nil
+		}
+	}
+
+	readFile := pass.ReadFile
+	if readFile == nil {
+		readFile = os.ReadFile
+	}
+	src, err := readFile(filename)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	if isGo {
+		file, err := parser.ParseFile(pass.Fset, filename, src,
+			parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			return fmt.Errorf("parse file: %w", err)
+		}
+		if ast.IsGenerated(file) {
+			// Skip generated files.
+			return nil
+		}
+		return a.checkFile(pass.Fset, report, file)
+	}
+
+	// Add the file to the file set, so that diagnostics and suggested fixes
+	// can be reported for it.
+	tf := pass.Fset.AddFile(filename, -1, len(src))
+	tf.SetLinesForContent(src)
+	return a.checkSource(report, tf, src, style)
+}
+
+// excluded returns whether the file is excluded.
+func (a *analyzer) excluded(filename string) bool {
 	for _, exclude := range a.excludes {
 		if exclude(filename) {
-			return nil
+			return true
 		}
 	}
+	return false
+}
+
+func (a *analyzer) checkFile(fset *token.FileSet, report func(analysis.Diagnostic), file *ast.File) error {
+	// Check whether the file is excluded.
+	filename := fset.File(file.Pos()).Name()
+	if a.excluded(filename) {
+		return nil
+	}
 
-	var header string
-	headerPos, headerEnd := file.FileStart, file.FileStart
+	loc := headerLocation{
+		filename: filename,
+		pos:      file.FileStart,
+		end:      file.FileStart,
+		insert:   file.FileStart,
+		report:   file.Package,
+		suffix:   "\n",
+		style:    a.headerFor(filename).commentStyle,
+	}
 	if len(file.Comments) > 0 {
 		if c := file.Comments[0]; c.Pos() < file.Package {
-			headerPos, headerEnd = c.Pos(), c.End()
+			loc.pos, loc.end = c.Pos(), c.End()
 			for _, comment := range c.List {
-				header += comment.Text + "\n"
+				loc.header += comment.Text + "\n"
 			}
 		}
 	}
 
-	if header == "" || !a.headerMatcher.MatchString(header) {
+	return a.checkHeader(report, loc)
+}
+
+// headerLocation is the location of a license header within a file.
+type headerLocation struct {
+	filename string
+
+	// header is the existing header comment, or empty if there is none.
+	header   string
+	pos, end token.Pos
+
+	// insert is the position new license headers are inserted at.
+	insert token.Pos
+
+	// report is the position missing license headers are reported at. If
+	// invalid, insert is used.
+	report token.Pos
+
+	// prefix is inserted before new license headers.
+	prefix string
+
+	// suffix is appended to updated license headers.
+	suffix string
+
+	// style is the comment style to use for the license header.
+	style CommentStyle
+}
+
+// checkHeader checks the license header of a file, reporting a diagnostic if
+// it is missing or needs to be updated.
+func (a *analyzer) checkHeader(report func(analysis.Diagnostic), loc headerLocation) error {
+	filename := loc.filename
+	h := a.headerFor(filename)
+
+	if loc.header == "" || !a.headerMatcher.MatchString(loc.header) {
 		// License header is missing, generate a new one.
-		newHeader, err := a.header.Create(filename)
+		pos := loc.report
+		if !pos.IsValid() {
+			pos = loc.insert
+		}
+		newHeader, err := h.create(filename, loc.style)
+		if reportTruncatedHistory(report, pos, token.NoPos, err) {
+			return nil
+		}
 		if err != nil {
 			return fmt.Errorf("create %s header: %w", filename, err)
 		}
-		pass.Report(analysis.Diagnostic{
-			Pos:      file.Package,
+		report(analysis.Diagnostic{
+			Pos:      pos,
 			Category: analyzerName,
 			Message:  "missing license header",
 			SuggestedFixes: []analysis.SuggestedFix{{
 				Message: "add license header",
 				TextEdits: []analysis.TextEdit{{
-					Pos:     file.FileStart,
-					NewText: []byte(newHeader + "\n"),
+					Pos:     loc.insert,
+					NewText: []byte(loc.prefix + newHeader + "\n"),
+				}},
+			}},
+		})
+		return nil
+	}
+
+	// Files outside the change scope keep their existing header, and only
+	// headers not matching the license header are reported, keeping their
+	// existing years.
+	scoped := h
+	changed := true
+	if a.changes != nil {
+		var err error
+		if changed, err = a.changes.changed(filename); err != nil {
+			return fmt.Errorf("find changes for %s: %w", filename, err)
+		}
+		if !changed {
+			scoped = h.withYearMode(YearModePreserve)
+		}
+	}
+
+	match, i := h.match(loc.header)
+	if !changed && (match == headerMatched || match == headerEquivalent) {
+		return nil
+	}
+	switch match {
+	case headerLegacy:
+		return checkLegacyHeader(report, loc, scoped, &scoped.legacy[i])
+	case headerMigration:
+		newHeader, err := scoped.migrate(&scoped.migrations[i], filename, loc.header, loc.style)
+		if reportTruncatedHistory(report, loc.pos, loc.end, err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("migrate %s header: %w", filename, err)
+		}
+		report(analysis.Diagnostic{
+			Pos:      loc.pos,
+			End:      loc.end,
+			Category: CategoryMigrate,
+			Message:  "license header requires migration",
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message: "migrate license header",
+				TextEdits: []analysis.TextEdit{{
+					Pos:     loc.pos,
+					End:     loc.end,
+					NewText: []byte(newHeader + loc.suffix),
 				}},
 			}},
 		})
 		return nil
 	}
+	if a.cfg.Strict && match == headerUnrecognised {
+		// License header is not recognised, offer to replace it or to add the
+		// license header before it.
+		newHeader, err := h.create(filename, loc.style)
+		if reportTruncatedHistory(report, loc.pos, loc.end, err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("create %s header: %w", filename, err)
+		}
+		report(analysis.Diagnostic{
+			Pos:     loc.pos,
+			End:     loc.end,
+			Message: "unrecognised license header",
+			SuggestedFixes: []analysis.SuggestedFix{
+				{
+					Message: "replace license header",
+					TextEdits: []analysis.TextEdit{{
+						Pos:     loc.pos,
+						End:     loc.end,
+						NewText: []byte(newHeader + loc.suffix),
+					}},
+				},
+				{
+					Message: "add license header",
+					TextEdits: []analysis.TextEdit{{
+						Pos:     loc.insert,
+						NewText: []byte(loc.prefix + newHeader + "\n"),
+					}},
+				},
+			},
+		})
+		return nil
+	}
 
-	newHeader, modified, err := a.header.Update(filename, header)
+	newHeader, modified, err := scoped.update(filename, loc.header, loc.style)
+	if reportTruncatedHistory(report, loc.pos, loc.end, err) {
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("update %s header: %w", filename, err)
 	}
 	if modified {
-		pass.Report(analysis.Diagnostic{
-			Pos:     headerPos,
-			End:     headerEnd,
+		report(analysis.Diagnostic{
+			Pos:     loc.pos,
+			End:     loc.end,
 			Message: "invalid license header",
 			SuggestedFixes: []analysis.SuggestedFix{{
 				Message: "update license header",
 				TextEdits: []analysis.TextEdit{{
-					Pos:     headerPos,
-					End:     headerEnd,
-					NewText: []byte(newHeader + "\n"),
+					Pos:     loc.pos,
+					End:     loc.end,
+					NewText: []byte(newHeader + loc.suffix),
 				}},
 			}},
 		})
@@ -225,3 +612,53 @@ func (a *analyzer) checkFile(pass *analysis.Pass, file *ast.File) error {
 
 	return nil
 }
+
+// checkLegacyHeader reports a legacy license header according to its
+// severity.
+func checkLegacyHeader(report func(analysis.Diagnostic), loc headerLocation, h *Header, legacy *legacyHeader) error {
+	switch legacy.severity {
+	case LegacyWarn:
+		report(analysis.Diagnostic{
+			Pos:     loc.pos,
+			End:     loc.end,
+			Message: "legacy license header",
+		})
+	case LegacyMigrate:
+		newHeader, _, err := h.updateMatched(legacy.matcher, loc.filename, loc.header, loc.style)
+		if reportTruncatedHistory(report, loc.pos, loc.end, err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("update %s header: %w", loc.filename, err)
+		}
+		report(analysis.Diagnostic{
+			Pos:     loc.pos,
+			End:     loc.end,
+			Message: "legacy license header",
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message: "update license header",
+				TextEdits: []analysis.TextEdit{{
+					Pos:     loc.pos,
+					End:     loc.end,
+					NewText: []byte(newHeader + loc.suffix),
+				}},
+			}},
+		})
+	}
+	return nil
+}
+
+// reportTruncatedHistory reports a diagnostic if err is caused by truncated
+// history when the shallow policy is ShallowFail, returning whether it was
+// reported.
+func reportTruncatedHistory(report func(analysis.Diagnostic), pos, end token.Pos, err error) bool {
+	if !errors.Is(err, ErrHistoryTruncated) {
+		return false
+	}
+	report(analysis.Diagnostic{
+		Pos:     pos,
+		End:     end,
+		Message: "cannot determine copyright year: history is truncated (shallow clone), fetch the full history or change the shallow policy",
+	})
+	return true
+}
@@ -21,12 +21,18 @@
 package golicenser
 
 import (
+	"go/token"
+	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
+	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
 )
 
 func init() {
@@ -92,6 +98,14 @@ func TestAnalyzer(t *testing.T) {
 			packageDir := filepath.Join(analysistest.TestData(), "src/packagecomment/")
 			_ = analysistest.RunWithSuggestedFixes(t, packageDir, a)
 		})
+
+		// Other files contains assembly and header files (OtherFiles), and Go
+		// files excluded by build constraints (IgnoredFiles).
+		t.Run("otherfiles", func(t *testing.T) {
+			t.Parallel()
+			packageDir := filepath.Join(analysistest.TestData(), "src/otherfiles/")
+			runWithGoldenFiles(t, packageDir, a)
+		})
 	})
 
 	t.Run("concurrency", func(t *testing.T) {
@@ -168,6 +182,172 @@ func TestAnalyzer(t *testing.T) {
 		})
 	})
 
+	t.Run("with rules", func(t *testing.T) {
+		t.Parallel()
+		cfg := Config{
+			Header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Test",
+				YearMode: YearModeThisYear,
+			},
+			Rules: []Rule{{
+				Paths: []string{"**/src/rules/**"},
+				Header: HeaderOpts{
+					Template: "Copyright {{.year}} {{.author}}. All rights reserved.",
+					Author:   "Rule Author",
+					YearMode: YearModeThisYear,
+				},
+			}},
+		}
+		a, err := NewAnalyzer(cfg)
+		if err != nil {
+			t.Fatalf("NewAnalyzer() err = %v", err)
+		}
+
+		// rules contains a file without a license header, matched by a rule
+		// with a different template and author.
+		t.Run("rules", func(t *testing.T) {
+			t.Parallel()
+			packageDir := filepath.Join(analysistest.TestData(), "src/rules/")
+			_ = analysistest.RunWithSuggestedFixes(t, packageDir, a)
+		})
+
+		// Outdated is not matched by any rule, and should use the default
+		// header.
+		t.Run("outdated", func(t *testing.T) {
+			t.Parallel()
+			packageDir := filepath.Join(analysistest.TestData(), "src/outdated/")
+			_ = analysistest.RunWithSuggestedFixes(t, packageDir, a)
+		})
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		t.Parallel()
+		cfg := Config{
+			Header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Test",
+				YearMode: YearModeThisYear,
+			},
+			Strict: true,
+		}
+		a, err := NewAnalyzer(cfg)
+		if err != nil {
+			t.Fatalf("NewAnalyzer() err = %v", err)
+		}
+
+		// strict contains a file with a copyright header that is not matched
+		// by the license header, which is reported in strict mode.
+		t.Run("strict", func(t *testing.T) {
+			t.Parallel()
+			packageDir := filepath.Join(analysistest.TestData(), "src/strict/")
+			_ = analysistest.RunWithSuggestedFixes(t, packageDir, a)
+		})
+
+		// Outdated contains a recognised license header, which should be
+		// updated as usual.
+		t.Run("outdated", func(t *testing.T) {
+			t.Parallel()
+			packageDir := filepath.Join(analysistest.TestData(), "src/outdated/")
+			_ = analysistest.RunWithSuggestedFixes(t, packageDir, a)
+		})
+	})
+
+	t.Run("with legacy headers", func(t *testing.T) {
+		t.Parallel()
+		cfg := Config{
+			Header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Test",
+				YearMode: YearModePreserve,
+				Legacy: []LegacyHeader{
+					{
+						Template: "Copyright (c) {{.year}} {{.author}}",
+						Author:   "Old Author",
+						Severity: LegacyAccept,
+					},
+					{
+						Template: "Copyright {{.year}} {{.author}}. All rights reserved.",
+						Severity: LegacyWarn,
+					},
+					{
+						Template: "Copyright (C) {{.year}} {{.author}}",
+						Severity: LegacyMigrate,
+					},
+				},
+			},
+		}
+		a, err := NewAnalyzer(cfg)
+		if err != nil {
+			t.Fatalf("NewAnalyzer() err = %v", err)
+		}
+
+		// legacy contains files with legacy headers, which are accepted,
+		// reported, or reported with a fix migrating them to the license
+		// header depending on the severity.
+		t.Run("legacy", func(t *testing.T) {
+			t.Parallel()
+			packageDir := filepath.Join(analysistest.TestData(), "src/legacy/")
+			_ = analysistest.RunWithSuggestedFixes(t, packageDir, a)
+		})
+	})
+
+	t.Run("with migrations", func(t *testing.T) {
+		t.Parallel()
+		cfg := Config{
+			Header: HeaderOpts{
+				Template:     "Copyright {{.year}} {{.author}}\nSPDX-License-Identifier: Apache-2.0",
+				Author:       "Test",
+				AuthorRegexp: ".+",
+				YearMode:     YearModePreserve,
+				Migrations: []Migration{{
+					FromMatcher: `Copyright \(c\) {{.year}} (?P<author>[A-Za-z ]+[a-z]).*\nUse of this source code is governed by an MIT license\.`,
+				}},
+			},
+		}
+		a, err := NewAnalyzer(cfg)
+		if err != nil {
+			t.Fatalf("NewAnalyzer() err = %v", err)
+		}
+
+		// migration contains a file with an MIT license header, which is
+		// migrated to Apache-2.0 keeping the year and copyright holder.
+		t.Run("migration", func(t *testing.T) {
+			t.Parallel()
+			packageDir := filepath.Join(analysistest.TestData(), "src/migration/")
+			_ = analysistest.RunWithSuggestedFixes(t, packageDir, a)
+		})
+	})
+
+	t.Run("with shallow history", func(t *testing.T) {
+		t.Parallel()
+		cfg := Config{
+			Header: HeaderOpts{
+				Template:      "Copyright (c) {{.year}} {{.author}}",
+				Author:        "Test",
+				YearMode:      YearModeGitRange,
+				ShallowPolicy: ShallowFail,
+			},
+			History: testHistory{
+				created:   yearTime(2024),
+				modified:  yearTime(2025),
+				truncated: true,
+			},
+		}
+		a, err := NewAnalyzer(cfg)
+		if err != nil {
+			t.Fatalf("NewAnalyzer() err = %v", err)
+		}
+
+		// shallow contains a file with truncated history, which is reported
+		// as the shallow policy is ShallowFail.
+		t.Run("shallow", func(t *testing.T) {
+			t.Parallel()
+			packageDir := filepath.Join(analysistest.TestData(), "src/shallow/")
+			_ = analysistest.Run(t, packageDir, a)
+		})
+	})
+
 	t.Run("with escaped matcher", func(t *testing.T) {
 		t.Parallel()
 		cfg := Config{
@@ -195,6 +375,153 @@ func TestAnalyzer(t *testing.T) {
 	})
 }
 
+// runWithGoldenFiles runs the analyzer on the package in dir using the analysis
+// driver, and checks that applying the suggested fixes to each file results in
+// its golden file. Unlike analysistest.RunWithSuggestedFixes, this includes
+// non-Go files and Go files excluded by build constraints, and files are
+// compared without formatting. Files without a golden file must not have any
+// diagnostics.
+func runWithGoldenFiles(t *testing.T, dir string, a *analysis.Analyzer) {
+	t.Helper()
+
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.LoadAllSyntax,
+		Dir:  dir,
+		Env:  append(os.Environ(), "GOPATH="+dir, "GO111MODULE=off", "GOWORK=off"),
+	}, ".")
+	if err != nil {
+		t.Fatalf("load %s: %v", dir, err)
+	}
+	graph, err := checker.Analyze([]*analysis.Analyzer{a}, pkgs, nil)
+	if err != nil {
+		t.Fatalf("analyze %s: %v", dir, err)
+	}
+
+	type fileEdits struct {
+		tf    *token.File
+		edits []analysis.TextEdit
+	}
+	files := make(map[string]*fileEdits)
+	for _, act := range graph.Roots {
+		if act.Err != nil {
+			t.Fatalf("analyze %s: %v", act.Package.PkgPath, act.Err)
+		}
+		for _, d := range act.Diagnostics {
+			tf := act.Package.Fset.File(d.Pos)
+			if len(d.SuggestedFixes) == 0 {
+				t.Errorf("%s: diagnostic %q has no suggested fix", tf.Name(), d.Message)
+				continue
+			}
+			if files[tf.Name()] == nil {
+				files[tf.Name()] = &fileEdits{tf: tf}
+			}
+			files[tf.Name()].edits = append(files[tf.Name()].edits, d.SuggestedFixes[0].TextEdits...)
+		}
+	}
+
+	goldens, err := filepath.Glob(filepath.Join(dir, "*.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, golden := range goldens {
+		filename := strings.TrimSuffix(golden, ".golden")
+		f, ok := files[filename]
+		if !ok {
+			t.Errorf("%s: no diagnostics, want fixes matching %s", filename, filepath.Base(golden))
+			continue
+		}
+		delete(files, filename)
+
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := applyEdits(f.tf, src, f.edits); string(got) != string(want) {
+			t.Errorf("%s fixed = %q, want %q", filepath.Base(filename), got, want)
+		}
+	}
+	for filename := range files {
+		t.Errorf("%s: unexpected diagnostics", filename)
+	}
+}
+
+func TestAnalyzerOtherFiles(t *testing.T) {
+	t.Parallel()
+
+	a, err := newAnalyzer(Config{
+		Header: HeaderOpts{
+			Template: "Copyright (c) {{.year}} {{.author}}",
+			Author:   "Test",
+			YearMode: YearModeThisYear,
+		},
+	})
+	if err != nil {
+		t.Fatalf("newAnalyzer() err = %v", err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]struct {
+		src       string
+		wantFixed string
+	}{
+		"cgo.c": {
+			src:       "#include <stdio.h>\n",
+			wantFixed: "// Copyright (c) 2025 Test\n\n#include <stdio.h>\n",
+		},
+		"ignored_linux.go": {
+			src:       "//go:build linux\n\npackage test\n",
+			wantFixed: "// Copyright (c) 2025 Test\n\n//go:build linux\n\npackage test\n",
+		},
+		"outdated.s": {
+			src:       "// Copyright (c) 2001 Test\n\nTEXT ·f(SB), $0\n",
+			wantFixed: "// Copyright (c) 2025 Test\n\nTEXT ·f(SB), $0\n",
+		},
+		"valid.h":   {src: "// Copyright (c) 2025 Test\n"},
+		"blob.syso": {src: "\x00\x01"},
+	}
+	pass := &analysis.Pass{
+		Fset:         token.NewFileSet(),
+		OtherFiles:   []string{},
+		IgnoredFiles: []string{},
+		ReadFile:     os.ReadFile,
+	}
+	for name, f := range files {
+		p := filepath.Join(dir, name)
+		if err = os.WriteFile(p, []byte(f.src), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Ext(name) == ".go" {
+			pass.IgnoredFiles = append(pass.IgnoredFiles, p)
+		} else {
+			pass.OtherFiles = append(pass.OtherFiles, p)
+		}
+	}
+
+	got := make(map[string]string)
+	pass.Report = func(d analysis.Diagnostic) {
+		tf := pass.Fset.File(d.Pos)
+		src, err := os.ReadFile(tf.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		fixed := applyEdits(tf, src, d.SuggestedFixes[0].TextEdits)
+		got[filepath.Base(tf.Name())] = string(fixed)
+	}
+	if _, err = a.run(pass); err != nil {
+		t.Fatalf("run() err = %v", err)
+	}
+
+	for name, f := range files {
+		if fixed := got[name]; fixed != f.wantFixed {
+			t.Errorf("%s fixed = %q, want %q", name, fixed, f.wantFixed)
+		}
+	}
+}
+
 func TestNewAnalyzer(t *testing.T) {
 	t.Parallel()
 
@@ -317,6 +644,73 @@ func TestNewAnalyzer(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "rules",
+			cfg: Config{
+				Header: header,
+				Rules: []Rule{
+					{
+						Paths:  []string{"**/third_party/**"},
+						Header: HeaderOpts{Template: "third party", Author: "test"},
+					},
+					{
+						Paths:  []string{"r!/pkg/", "**/internal/enterprise/**"},
+						Header: HeaderOpts{Template: "pkg", Author: "test"},
+					},
+					{
+						Paths:  []string{"**/pkg/**"},
+						Header: HeaderOpts{Template: "unreachable", Author: "test"},
+					},
+				},
+			},
+			check: func(t *testing.T, a *analyzer) {
+				t.Helper()
+
+				tests := map[string]*Header{
+					"/repo/main.go":                        a.header,
+					"/repo/third_party/lib/lib.go":         a.rules[0].header,
+					"/repo/pkg/lib/lib.go":                 a.rules[1].header,
+					"/repo/internal/enterprise/feature.go": a.rules[1].header,
+					"/repo/internal/other/other.go":        a.header,
+				}
+				for path, want := range tests {
+					if got := a.headerFor(path); got != want {
+						t.Errorf("headerFor(%q) = %p, want %p", path, got, want)
+					}
+				}
+			},
+		},
+		{
+			name: "non-Go comment style",
+			cfg: Config{
+				Header: HeaderOpts{Template: "test", Author: "test", CommentStyle: CommentStyleHash},
+			},
+			wantErr: true,
+		},
+		{
+			name: "rule without paths",
+			cfg: Config{
+				Header: header,
+				Rules:  []Rule{{Header: header}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "rule invalid pattern",
+			cfg: Config{
+				Header: header,
+				Rules:  []Rule{{Paths: []string{"r!test)"}, Header: header}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "rule invalid header",
+			cfg: Config{
+				Header: header,
+				Rules:  []Rule{{Paths: []string{"**"}, Header: HeaderOpts{Template: "test"}}},
+			},
+			wantErr: true,
+		},
 		{
 			name: "excludes invalid doublestar",
 			cfg: Config{
@@ -0,0 +1,294 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golicenser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// cacheVersion is the version of the cache entry format. It is part of every
+// cache key, so it must be changed when the check results may differ for the
+// same input.
+const cacheVersion = "golicenser-cache-v1"
+
+// errNoHistoryState is returned by historyState when the state of the history
+// is unknown.
+var errNoHistoryState = errors.New("unknown history state")
+
+// historyStater is implemented by histories that can describe the state of the
+// history of a file. The state changes whenever the history of the file may
+// have changed, and is used to invalidate cached results.
+type historyStater interface {
+	historyState(filename string) (string, error)
+}
+
+// resultCache is an on-disk cache of check results. Results are keyed by the
+// file name and content, the configuration and the state of everything else
+// that affects the result, such as the current year and the file history.
+type resultCache struct {
+	dir string
+
+	// config is the hash of the configuration.
+	config string
+}
+
+// cacheEntry is a cached check result.
+type cacheEntry struct {
+	Issues []Issue `json:"issues"`
+}
+
+// newResultCache creates a result cache in the directory for the
+// configuration.
+func newResultCache(dir string, cfg Config) (*resultCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cache directory: %w", err)
+	}
+	// Options that do not affect the results are left out of the key, so that
+	// changing them keeps the cached results. The change scope is part of the
+	// key of each file instead.
+	cfg.MaxConcurrent, cfg.MaxProcesses = 0, 0
+	cfg.Since, cfg.Staged = "", false
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("hash config: %w", err)
+	}
+	h := sha256.New()
+	writeCacheKeyPart(h, cacheVersion)
+	writeCacheKeyPart(h, buildVersion())
+	writeCacheKeyPart(h, string(b))
+	return &resultCache{dir: dir, config: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// buildVersion returns the version of the golicenser module in the running
+// binary, so that results are not shared between versions.
+func buildVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	const path = "github.com/joshuasing/golicenser"
+	if bi.Main.Path == path {
+		version := bi.Main.Version
+		for _, s := range bi.Settings {
+			if s.Key == "vcs.revision" || s.Key == "vcs.modified" {
+				version += " " + s.Value
+			}
+		}
+		return version
+	}
+	for _, dep := range bi.Deps {
+		if dep.Path == path {
+			return dep.Version + " " + dep.Sum
+		}
+	}
+	return ""
+}
+
+// writeCacheKeyPart writes a length-prefixed part of a cache key.
+func writeCacheKeyPart(h hash.Hash, s string) {
+	h.Write([]byte(strconv.Itoa(len(s)) + ":" + s))
+}
+
+// key returns the cache key for the parts.
+func (c *resultCache) key(parts ...string) string {
+	h := sha256.New()
+	writeCacheKeyPart(h, c.config)
+	for _, part := range parts {
+		writeCacheKeyPart(h, part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// path returns the path of the cache entry for the key.
+func (c *resultCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// get returns the cached issues for the key, and whether they were found.
+func (c *resultCache) get(key string) ([]Issue, bool) {
+	b, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var e cacheEntry
+	if err = json.Unmarshal(b, &e); err != nil {
+		return nil, false
+	}
+	return e.Issues, true
+}
+
+// put stores the issues for the key. The entry is written to a temporary file
+// and renamed, so that concurrent runs never read a partial entry.
+func (c *resultCache) put(key string, issues []Issue) error {
+	b, err := json.Marshal(cacheEntry{Issues: issues})
+	if err != nil {
+		return err
+	}
+	path := c.path(key)
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(b); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err = f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// gitHeads are the HEAD commits of Git repositories, keyed by the repository
+// root.
+var gitHeads sync.Map // map[string]*gitHeadEntry
+
+// gitHeadEntry is the HEAD commit of a Git repository.
+type gitHeadEntry struct {
+	once sync.Once
+	head string
+}
+
+// gitHead returns the HEAD commit of the repository, or an empty string if the
+// repository has no commits.
+func gitHead(root string) string {
+	v, _ := gitHeads.LoadOrStore(root, &gitHeadEntry{})
+	e := v.(*gitHeadEntry)
+	e.once.Do(func() {
+		out, err := gitOutput("-C", root, "rev-parse", "-q", "--verify", "HEAD")
+		if err == nil {
+			e.head = strings.TrimSpace(string(out))
+		}
+	})
+	return e.head
+}
+
+// historyState returns the HEAD commit of the repository, the shallow
+// boundary commits and the file modification time, which is used for files
+// with uncommitted changes. The shallow boundary commits change when a
+// shallow clone is deepened or unshallowed, without changing HEAD.
+func (g *gitHistory) historyState(filename string) (string, error) {
+	root, _, err := gitPath(filename)
+	if err != nil {
+		return "", err
+	}
+	b, err := readGitShallow(root)
+	if err != nil {
+		return "", err
+	}
+	shallow := "none"
+	if b != nil {
+		sum := sha256.Sum256(b)
+		shallow = hex.EncodeToString(sum[:])
+	}
+	modTime, err := fsModTime(filename)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join([]string{
+		"git", gitHead(root), shallow, g.dateFormat, g.ignore.key,
+		strconv.FormatInt(modTime.UnixNano(), 10),
+	}, "\x00"), nil
+}
+
+// historyState returns the file modification time.
+func (fsHistory) historyState(filename string) (string, error) {
+	modTime, err := fsModTime(filename)
+	if err != nil {
+		return "", err
+	}
+	return "fs\x00" + strconv.FormatInt(modTime.UnixNano(), 10), nil
+}
+
+// historyState returns the history of the file in the JSON history.
+func (h *jsonHistory) historyState(filename string) (string, error) {
+	fh, err := h.file(filename)
+	if errors.Is(err, ErrNotInHistory) {
+		return "json\x00", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(fh)
+	if err != nil {
+		return "", err
+	}
+	return "json\x00" + string(b), nil
+}
+
+// cacheKey returns the cache key for the check result of a file, and whether
+// the result can be cached. Results cannot be cached if the state of the file
+// history is unknown, such as when using a custom History.
+func (l *Linter) cacheKey(filename string, src []byte) (string, bool) {
+	if l.cache == nil {
+		return "", false
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return "", false
+	}
+	h := l.a.headerFor(filename)
+	sum := sha256.Sum256(src)
+	parts := []string{abs, hex.EncodeToString(sum[:]), strconv.Itoa(h.in(h.now()).Year())}
+	if l.a.changes != nil {
+		changed, err := l.a.changes.changed(filename)
+		if err != nil {
+			return "", false
+		}
+		parts = append(parts, strconv.FormatBool(changed))
+	}
+	if p, _ := h.yearMode.policy(); p.usesHistory() {
+		hs, ok := h.history.(historyStater)
+		if !ok {
+			return "", false
+		}
+		state, err := hs.historyState(filename)
+		if err != nil {
+			return "", false
+		}
+		parts = append(parts, state)
+	}
+	return l.cache.key(parts...), true
+}
+
+// historyState returns the history state of the memoized history.
+func (m *memoHistory) historyState(filename string) (string, error) {
+	if hs, ok := m.h.(historyStater); ok {
+		return hs.historyState(filename)
+	}
+	return "", errNoHistoryState
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golicenser
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResultCache(t *testing.T) {
+	t.Parallel()
+
+	c, err := newResultCache(t.TempDir(), Config{})
+	if err != nil {
+		t.Fatalf("newResultCache() err = %v", err)
+	}
+	key := c.key("a.go", "content")
+	if _, ok := c.get(key); ok {
+		t.Errorf("get() before put ok = true, want false")
+	}
+	want := []Issue{{Message: "missing license header", Fixed: []byte("fixed")}}
+	if err = c.put(key, want); err != nil {
+		t.Fatalf("put() err = %v", err)
+	}
+	got, ok := c.get(key)
+	if !ok {
+		t.Fatalf("get() after put ok = false, want true")
+	}
+	if len(got) != 1 || got[0].Message != want[0].Message || string(got[0].Fixed) != "fixed" {
+		t.Errorf("get() = %+v, want %+v", got, want)
+	}
+
+	// Parts are length-prefixed, so different splits have different keys.
+	if c.key("ab", "c") == c.key("a", "bc") {
+		t.Errorf("key() is ambiguous")
+	}
+
+	// Options not affecting the results do not change the key.
+	c2, err := newResultCache(t.TempDir(), Config{MaxConcurrent: 4, MaxProcesses: 2})
+	if err != nil {
+		t.Fatalf("newResultCache() err = %v", err)
+	}
+	if c2.key("a.go", "content") != key {
+		t.Errorf("key() changed with MaxConcurrent and MaxProcesses")
+	}
+	c3, err := newResultCache(t.TempDir(), Config{Strict: true})
+	if err != nil {
+		t.Fatalf("newResultCache() err = %v", err)
+	}
+	if c3.key("a.go", "content") == key {
+		t.Errorf("key() not changed with Strict")
+	}
+}
+
+func TestLinterCacheKey(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "a.go")
+	writeFile(t, filename, "package a\n")
+	src := []byte("package a\n")
+
+	clock := func(year int) func() time.Time {
+		return func() time.Time {
+			return time.Date(year, time.March, 1, 0, 0, 0, 0, time.UTC)
+		}
+	}
+	newLinter := func(author string, ym YearMode, history History, year int) *Linter {
+		t.Helper()
+		l, err := NewLinter(Config{
+			Header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   author,
+				YearMode: ym,
+			},
+			History:  history,
+			Clock:    clock(year),
+			CacheDir: filepath.Join(dir, "cache"),
+		})
+		if err != nil {
+			t.Fatalf("NewLinter() err = %v", err)
+		}
+		return l
+	}
+	cacheKey := func(l *Linter, src []byte) string {
+		t.Helper()
+		key, ok := l.cacheKey(filename, src)
+		if !ok {
+			t.Fatalf("cacheKey() ok = false, want true")
+		}
+		return key
+	}
+
+	l := newLinter("Test", YearModeThisYear, FSHistory(), 2025)
+	key := cacheKey(l, src)
+	if got := cacheKey(newLinter("Test", YearModeThisYear, FSHistory(), 2025), src); got != key {
+		t.Errorf("cacheKey() with same config = %s, want %s", got, key)
+	}
+	for name, got := range map[string]string{
+		"content": cacheKey(l, []byte("package b\n")),
+		"author":  cacheKey(newLinter("Other", YearModeThisYear, FSHistory(), 2025), src),
+		"year":    cacheKey(newLinter("Test", YearModeThisYear, FSHistory(), 2026), src),
+		"mode":    cacheKey(newLinter("Test", YearModeLastModified, FSHistory(), 2025), src),
+	} {
+		if got == key {
+			t.Errorf("cacheKey() with different %s = %s, want different key", name, got)
+		}
+	}
+
+	// The history state is part of the key for year modes using history.
+	l = newLinter("Test", YearModeLastModified, FSHistory(), 2025)
+	key = cacheKey(l, src)
+	modTime := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filename, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	if got := cacheKey(l, src); got == key {
+		t.Errorf("cacheKey() after modification = %s, want different key", got)
+	}
+
+	// Results cannot be cached without a known history state.
+	l = newLinter("Test", YearModeLastModified, testHistory{}, 2025)
+	if _, ok := l.cacheKey(filename, src); ok {
+		t.Errorf("cacheKey() with unknown history ok = true, want false")
+	}
+}
+
+func TestLinterCache(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "a.go")
+	l, err := NewLinter(Config{
+		Header: HeaderOpts{
+			Template: "Copyright (c) {{.year}} {{.author}}",
+			Author:   "Test",
+			YearMode: YearModeThisYear,
+		},
+		CacheDir: filepath.Join(dir, "cache"),
+	})
+	if err != nil {
+		t.Fatalf("NewLinter() err = %v", err)
+	}
+
+	src := []byte("package a\n")
+	want, err := l.Check(filename, src)
+	if err != nil {
+		t.Fatalf("Check() err = %v", err)
+	}
+	key, ok := l.cacheKey(filename, src)
+	if !ok {
+		t.Fatalf("cacheKey() ok = false, want true")
+	}
+	cached, ok := l.cache.get(key)
+	if !ok {
+		t.Fatalf("Check() did not cache the result")
+	}
+	if len(cached) != len(want) || string(cached[0].Fixed) != string(want[0].Fixed) {
+		t.Errorf("cached = %+v, want %+v", cached, want)
+	}
+
+	// Cached results are returned without checking the file.
+	cached[0].Message = "cached"
+	if err = l.cache.put(key, cached); err != nil {
+		t.Fatal(err)
+	}
+	got, err := l.Check(filename, src)
+	if err != nil {
+		t.Fatalf("Check() err = %v", err)
+	}
+	if len(got) != 1 || got[0].Message != "cached" {
+		t.Errorf("Check() = %+v, want cached result", got)
+	}
+}
+
+func TestLinterCacheShallow(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	tmp := t.TempDir()
+	origin := filepath.Join(tmp, "origin")
+	writeFile(t, filepath.Join(origin, "a.go"), "package a\n")
+	runGit(t, origin, "", "init", "-q")
+	runGit(t, origin, "2019-05-01T00:00:00Z", "add", ".")
+	runGit(t, origin, "2019-05-01T00:00:00Z", "commit", "-q", "-m", "add a.go")
+	writeFile(t, filepath.Join(origin, "a.go"), "package a\n\nvar A = 1\n")
+	runGit(t, origin, "2026-05-01T00:00:00Z", "commit", "-q", "-am", "modify a.go")
+	runGit(t, tmp, "", "clone", "-q", "--depth=1", "file://"+filepath.ToSlash(origin), "clone")
+
+	l, err := NewLinter(Config{
+		Header: HeaderOpts{
+			Template: "Copyright (c) {{.year}} {{.author}}",
+			Author:   "Test",
+			YearMode: YearModeGitRange,
+		},
+		History:  GitHistory(),
+		Clock:    func() time.Time { return time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC) },
+		CacheDir: filepath.Join(tmp, "cache"),
+	})
+	if err != nil {
+		t.Fatalf("NewLinter() err = %v", err)
+	}
+
+	filename := filepath.Join(tmp, "clone", "a.go")
+	src := []byte("package a\n\nvar A = 1\n")
+	if _, err = l.Check(filename, src); err != nil {
+		t.Fatalf("Check() err = %v", err)
+	}
+	shallowKey, ok := l.cacheKey(filename, src)
+	if !ok {
+		t.Fatalf("cacheKey() ok = false, want true")
+	}
+	if _, ok = l.cache.get(shallowKey); !ok {
+		t.Fatalf("Check() did not cache the result")
+	}
+
+	// Unshallowing changes the history without changing HEAD or the file, so
+	// the truncated result must not be used.
+	runGit(t, filepath.Join(tmp, "clone"), "", "fetch", "-q", "--unshallow")
+	key, ok := l.cacheKey(filename, src)
+	if !ok {
+		t.Fatalf("cacheKey() after unshallow ok = false, want true")
+	}
+	if key == shallowKey {
+		t.Errorf("cacheKey() after unshallow = %s, want different key", key)
+	}
+	if _, ok = l.cache.get(key); ok {
+		t.Errorf("get() after unshallow ok = true, want cache miss")
+	}
+}
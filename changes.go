@@ -0,0 +1,110 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golicenser
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+)
+
+// gitChanges is the set of files changed in Git, used to limit year updates to
+// files changed on the current branch or staged for commit. Changed files are
+// listed once per repository.
+type gitChanges struct {
+	// since is the base ref files are compared to, e.g. "origin/main".
+	since string
+
+	// staged is whether files with staged changes are changed.
+	staged bool
+
+	// repos are the changed files of each repository, keyed by the
+	// repository root.
+	repos sync.Map // map[string]*gitChangesEntry
+}
+
+// gitChangesEntry is the changed files of a repository.
+type gitChangesEntry struct {
+	once  sync.Once
+	files map[string]bool
+	err   error
+}
+
+// newGitChanges returns the changed files since the base ref and/or staged for
+// commit, or nil if neither is used.
+func newGitChanges(since string, staged bool) *gitChanges {
+	if since == "" && !staged {
+		return nil
+	}
+	return &gitChanges{since: since, staged: staged}
+}
+
+// changed returns whether the file has been changed.
+func (c *gitChanges) changed(filename string) (bool, error) {
+	root, rel, err := gitPath(filename)
+	if err != nil {
+		return false, err
+	}
+	v, _ := c.repos.LoadOrStore(root, &gitChangesEntry{})
+	e := v.(*gitChangesEntry)
+	e.once.Do(func() {
+		e.files, e.err = gitChangedFiles(root, c.since, c.staged)
+	})
+	if e.err != nil {
+		return false, e.err
+	}
+	return e.files[rel], nil
+}
+
+// gitChangedFiles returns the files in the repository that have changed since
+// the merge base of the base ref, including uncommitted and untracked files,
+// and the files with staged changes if staged is true. Files are keyed by the
+// slash-separated path relative to the repository root.
+func gitChangedFiles(root, since string, staged bool) (map[string]bool, error) {
+	files := make(map[string]bool)
+	add := func(args ...string) error {
+		args = append([]string{"-C", root, "-c", "core.quotePath=false"}, args...)
+		out, err := gitOutput(args...)
+		if err != nil {
+			return err
+		}
+		for _, name := range bytes.Split(out, []byte{0}) {
+			if len(name) > 0 {
+				files[string(name)] = true
+			}
+		}
+		return nil
+	}
+	if since != "" {
+		if err := add("diff", "--name-only", "-z", "--merge-base", since); err != nil {
+			return nil, fmt.Errorf("git diff %s: %w", since, err)
+		}
+		if err := add("ls-files", "--others", "--exclude-standard", "-z"); err != nil {
+			return nil, fmt.Errorf("git ls-files: %w", err)
+		}
+	}
+	if staged {
+		if err := add("diff", "--cached", "--name-only", "-z"); err != nil {
+			return nil, fmt.Errorf("git diff --cached: %w", err)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golicenser
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestGitChanges(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		runGit(t, dir, "2024-05-01T00:00:00Z", args...)
+	}
+	for _, name := range []string{"a.go", "b.go", "c.go", "d.go"} {
+		writeFile(t, filepath.Join(dir, name), "package a\n")
+	}
+	git("init", "-q", "-b", "main")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial commit")
+	git("checkout", "-q", "-b", "feature")
+	writeFile(t, filepath.Join(dir, "a.go"), "package a\n\nvar A = 1\n")
+	git("commit", "-q", "-am", "modify a.go")
+	writeFile(t, filepath.Join(dir, "b.go"), "package a\n\nvar B = 1\n")
+	git("add", "b.go")
+	writeFile(t, filepath.Join(dir, "c.go"), "package a\n\nvar C = 1\n")
+	writeFile(t, filepath.Join(dir, "e.go"), "package a\n")
+
+	tests := []struct {
+		name   string
+		since  string
+		staged bool
+		want   map[string]bool
+	}{
+		{
+			name:  "since",
+			since: "main",
+			want:  map[string]bool{"a.go": true, "b.go": true, "c.go": true, "e.go": true},
+		},
+		{
+			name:   "staged",
+			staged: true,
+			want:   map[string]bool{"b.go": true},
+		},
+		{
+			name:   "since and staged",
+			since:  "feature",
+			staged: true,
+			want:   map[string]bool{"b.go": true, "c.go": true, "e.go": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := newGitChanges(tt.since, tt.staged)
+			for _, name := range []string{"a.go", "b.go", "c.go", "d.go", "e.go"} {
+				got, err := c.changed(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatalf("changed(%q) err = %v", name, err)
+				}
+				if got != tt.want[name] {
+					t.Errorf("changed(%q) = %v, want %v", name, got, tt.want[name])
+				}
+			}
+		})
+	}
+
+	if c := newGitChanges("", false); c != nil {
+		t.Errorf("newGitChanges() = %v, want nil", c)
+	}
+	c := newGitChanges("missing-branch", false)
+	if _, err := c.changed(filepath.Join(dir, "a.go")); err == nil {
+		t.Errorf("changed() with missing ref err = nil, want error")
+	}
+
+	// Years are only updated for changed files.
+	a, err := newAnalyzer(Config{
+		Header: HeaderOpts{
+			Template: "Copyright (c) {{.year}} {{.author}}",
+			Author:   "Joshua Sing",
+			YearMode: YearModeThisYear,
+		},
+		Since: "main",
+	})
+	if err != nil {
+		t.Fatalf("newAnalyzer() err = %v", err)
+	}
+	for _, tt := range []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "a.go", header: "// Copyright (c) 2020 Joshua Sing\n", want: true},
+		{name: "d.go", header: "// Copyright (c) 2020 Joshua Sing\n", want: false},
+		// Headers of unchanged files are kept as-is, even if the years are
+		// not normalised.
+		{name: "d.go", header: "// Copyright (c) 2015 - 2019 Joshua Sing\n", want: false},
+		{name: "d.go", header: "// Copyright (c) 2016,2018 Joshua Sing\n", want: false},
+		{name: "d.go", header: "/* Copyright (c) 2020 Joshua Sing */\n", want: false},
+	} {
+		var diagnostics []analysis.Diagnostic
+		report := func(d analysis.Diagnostic) {
+			diagnostics = append(diagnostics, d)
+		}
+		err = a.checkHeader(report, headerLocation{
+			filename: filepath.Join(dir, tt.name),
+			header:   tt.header,
+			pos:      1,
+			end:      2,
+			insert:   1,
+			suffix:   "\n",
+		})
+		if err != nil {
+			t.Fatalf("checkHeader(%q, %q) err = %v", tt.name, tt.header, err)
+		}
+		if got := len(diagnostics) > 0; got != tt.want {
+			t.Errorf("checkHeader(%q, %q) reported = %v, want %v", tt.name, tt.header, got, tt.want)
+		}
+	}
+}
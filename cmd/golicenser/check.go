@@ -0,0 +1,40 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import "path/filepath"
+
+// runCheck runs the check command, which checks the license headers of Go
+// files without loading packages.
+func runCheck(args []string) int {
+	flags := commandFlagSet("check", "[-flag] [path ...]",
+		"Checks license headers of Go files by parsing only their comments and package clause, without\n"+
+			"loading or type-checking packages. This is much faster than the analysis driver on large\n"+
+			"repositories, as the package dependencies are not loaded.")
+	fix := flags.Bool("fix", false, "apply all suggested fixes")
+	all := flags.Bool("all", false, "also check non-Go files, as done by the files command")
+	_ = flags.Parse(args)
+
+	include := func(path string) bool {
+		return *all || filepath.Ext(path) == ".go"
+	}
+	return lint(flags.Args(), *fix, include)
+}
@@ -0,0 +1,310 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/joshuasing/golicenser"
+)
+
+// configVersion is the current configuration file version.
+const configVersion = 1
+
+// configFileNames are the file names searched for when looking for a
+// configuration file, in order of preference.
+var configFileNames = []string{".golicenser.yaml", ".golicenser.yml"}
+
+// configFile is the golicenser configuration file format.
+type configFile struct {
+	// path is the path of the loaded configuration file. Relative paths in the
+	// configuration file are resolved relative to the directory of this file.
+	path string
+
+	Version      int `yaml:"version"`
+	configHeader `yaml:",inline"`
+
+	Rules                  []configRule `yaml:"rules"`
+	Exclude                []string     `yaml:"exclude"`
+	MaxConcurrent          *int         `yaml:"max-concurrent"`
+	MaxProcesses           *int         `yaml:"max-processes"`
+	CopyrightHeaderMatcher string       `yaml:"copyright-header-matcher"`
+	Strict                 *bool        `yaml:"strict"`
+	History                string       `yaml:"history"`
+	HistoryFile            string       `yaml:"history-file"`
+	GitDate                string       `yaml:"git-date"`
+	IgnoreRevsFile         string       `yaml:"ignore-revs-file"`
+	IgnoreRevs             []string     `yaml:"ignore-revs"`
+	IgnoreMessages         []string     `yaml:"ignore-messages"`
+}
+
+// configHeader is the license header configuration.
+type configHeader struct {
+	Template      string                    `yaml:"template"`
+	TemplateFile  string                    `yaml:"template-file"`
+	SPDX          string                    `yaml:"spdx"`
+	Matcher       string                    `yaml:"matcher"`
+	MatcherFile   string                    `yaml:"matcher-file"`
+	MatcherEscape *bool                     `yaml:"matcher-escape"`
+	Author        string                    `yaml:"author"`
+	AuthorRegexp  string                    `yaml:"author-regexp"`
+	Variables     map[string]configVar      `yaml:"variables"`
+	YearMode      *golicenser.YearMode      `yaml:"year-mode"`
+	ShallowPolicy *golicenser.ShallowPolicy `yaml:"shallow-policy"`
+	Timezone      string                    `yaml:"timezone"`
+	CommentStyle  *golicenser.CommentStyle  `yaml:"comment-style"`
+	Legacy        []configLegacy            `yaml:"legacy"`
+	Migrations    []configMigration         `yaml:"migrations"`
+}
+
+// configRule is a license header rule for a set of paths. Unset header
+// options are inherited from the top-level configuration.
+type configRule struct {
+	Paths        []string `yaml:"paths"`
+	configHeader `yaml:",inline"`
+}
+
+// configLegacy is a legacy license header in the configuration file.
+type configLegacy struct {
+	Template      string                    `yaml:"template"`
+	TemplateFile  string                    `yaml:"template-file"`
+	Matcher       string                    `yaml:"matcher"`
+	MatcherFile   string                    `yaml:"matcher-file"`
+	MatcherEscape bool                      `yaml:"matcher-escape"`
+	Author        string                    `yaml:"author"`
+	AuthorRegexp  string                    `yaml:"author-regexp"`
+	Severity      golicenser.LegacySeverity `yaml:"severity"`
+}
+
+// configMigration is a license header migration in the configuration file.
+type configMigration struct {
+	From              string   `yaml:"from"`
+	FromFile          string   `yaml:"from-file"`
+	FromMatcher       string   `yaml:"from-matcher"`
+	FromMatcherFile   string   `yaml:"from-matcher-file"`
+	FromMatcherEscape bool     `yaml:"from-matcher-escape"`
+	To                string   `yaml:"to"`
+	ToFile            string   `yaml:"to-file"`
+	Carry             []string `yaml:"carry"`
+}
+
+// configVar is a template variable in the configuration file.
+type configVar struct {
+	Value  string `yaml:"value"`
+	Regexp string `yaml:"regexp"`
+}
+
+// findConfigFile searches for a configuration file in dir and each of its
+// parent directories. An empty string is returned if no configuration file is
+// found.
+func findConfigFile(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		for _, name := range configFileNames {
+			p := filepath.Join(dir, name)
+			info, err := os.Stat(p)
+			if err == nil && !info.IsDir() {
+				return p, nil
+			}
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return "", err
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// loadConfigFile reads and decodes the configuration file at path.
+func loadConfigFile(path string) (*configFile, error) {
+	//nolint:gosec // Reading user-defined file.
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cf := &configFile{path: path}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err = dec.Decode(cf); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	if cf.Version != configVersion {
+		return nil, fmt.Errorf("%s: unsupported config version %d (want %d)",
+			path, cf.Version, configVersion)
+	}
+	return cf, nil
+}
+
+// rules returns the configured rules. Unset rule options are inherited from
+// base.
+func (cf *configFile) rules(base golicenser.HeaderOpts) ([]golicenser.Rule, error) {
+	rules := make([]golicenser.Rule, 0, len(cf.Rules))
+	for i, r := range cf.Rules {
+		if r.Template == "" && r.TemplateFile == "" && r.SPDX == "" {
+			return nil, fmt.Errorf("rule %d: missing template", i)
+		}
+		tmpl, err := readTemplate(r.Template, cf.resolve(r.TemplateFile))
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		match, err := readTemplate(r.Matcher, cf.resolve(r.MatcherFile))
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+
+		h := base
+		h.Template, h.SPDX, h.Matcher, h.MatcherEscape = tmpl, r.SPDX, match, false
+		if r.MatcherEscape != nil {
+			h.MatcherEscape = *r.MatcherEscape
+		}
+		if r.Author != "" {
+			h.Author, h.AuthorRegexp = r.Author, ""
+		}
+		if r.AuthorRegexp != "" {
+			h.AuthorRegexp = r.AuthorRegexp
+		}
+		if len(r.Variables) > 0 {
+			h.Variables = make(map[string]*golicenser.Var, len(base.Variables)+len(r.Variables))
+			for name, v := range base.Variables {
+				h.Variables[name] = &golicenser.Var{Value: v.Value, Regexp: v.Regexp}
+			}
+			for name, v := range r.Variables {
+				h.Variables[name] = &golicenser.Var{Value: v.Value, Regexp: v.Regexp}
+			}
+		}
+		if r.YearMode != nil {
+			h.YearMode = *r.YearMode
+		}
+		if r.ShallowPolicy != nil {
+			h.ShallowPolicy = *r.ShallowPolicy
+		}
+		if r.Timezone != "" {
+			h.Timezone = r.Timezone
+		}
+		if r.CommentStyle != nil {
+			h.CommentStyle = *r.CommentStyle
+		}
+		if len(r.Legacy) > 0 {
+			if h.Legacy, err = cf.legacy(r.Legacy); err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i, err)
+			}
+		}
+		if len(r.Migrations) > 0 {
+			if h.Migrations, err = cf.migrations(r.Migrations); err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i, err)
+			}
+		}
+
+		rules = append(rules, golicenser.Rule{Paths: r.Paths, Header: h})
+	}
+	return rules, nil
+}
+
+// legacy returns the configured legacy license headers.
+func (cf *configFile) legacy(ls []configLegacy) ([]golicenser.LegacyHeader, error) {
+	legacy := make([]golicenser.LegacyHeader, 0, len(ls))
+	for i, l := range ls {
+		tmpl, err := readTemplate(l.Template, cf.resolve(l.TemplateFile))
+		if err != nil {
+			return nil, fmt.Errorf("legacy header %d: %w", i, err)
+		}
+		match, err := readTemplate(l.Matcher, cf.resolve(l.MatcherFile))
+		if err != nil {
+			return nil, fmt.Errorf("legacy header %d: %w", i, err)
+		}
+		legacy = append(legacy, golicenser.LegacyHeader{
+			Template:      tmpl,
+			Matcher:       match,
+			MatcherEscape: l.MatcherEscape,
+			Author:        l.Author,
+			AuthorRegexp:  l.AuthorRegexp,
+			Severity:      l.Severity,
+		})
+	}
+	return legacy, nil
+}
+
+// migrations returns the configured license header migrations.
+func (cf *configFile) migrations(ms []configMigration) ([]golicenser.Migration, error) {
+	migrations := make([]golicenser.Migration, 0, len(ms))
+	for i, m := range ms {
+		from, err := readTemplate(m.From, cf.resolve(m.FromFile))
+		if err != nil {
+			return nil, fmt.Errorf("migration %d: %w", i, err)
+		}
+		fromMatch, err := readTemplate(m.FromMatcher, cf.resolve(m.FromMatcherFile))
+		if err != nil {
+			return nil, fmt.Errorf("migration %d: %w", i, err)
+		}
+		to, err := readTemplate(m.To, cf.resolve(m.ToFile))
+		if err != nil {
+			return nil, fmt.Errorf("migration %d: %w", i, err)
+		}
+		migrations = append(migrations, golicenser.Migration{
+			From:              from,
+			FromMatcher:       fromMatch,
+			FromMatcherEscape: m.FromMatcherEscape,
+			To:                to,
+			Carry:             m.Carry,
+		})
+	}
+	return migrations, nil
+}
+
+// readTemplate returns the template, or if empty, the contents of the template
+// file. Built-in templates can be used by providing their SPDX identifier.
+func readTemplate(tmpl, file string) (string, error) {
+	if tmpl == "" && file != "" {
+		//nolint:gosec // Reading user-defined file.
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+	if tm, ok := golicenser.TemplateBySPDX(tmpl); ok {
+		return tm, nil
+	}
+	return tmpl, nil
+}
+
+// resolve returns p relative to the directory of the configuration file.
+func (cf *configFile) resolve(p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(filepath.Dir(cf.path), p)
+}
@@ -0,0 +1,296 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshuasing/golicenser"
+)
+
+func TestFindConfigFile(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := findConfigFile(sub)
+	if err != nil {
+		t.Fatalf("findConfigFile() err = %v", err)
+	}
+	if got != "" {
+		t.Errorf("findConfigFile() = %q, want none", got)
+	}
+
+	want := filepath.Join(root, "a", ".golicenser.yaml")
+	if err = os.WriteFile(want, []byte("version: 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err = findConfigFile(sub)
+	if err != nil {
+		t.Fatalf("findConfigFile() err = %v", err)
+	}
+	if got != want {
+		t.Errorf("findConfigFile() = %q, want %q", got, want)
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+		check   func(t *testing.T, cf *configFile)
+	}{
+		{
+			name: "full",
+			content: `version: 1
+template: MIT
+author: Joshua Sing
+variables:
+  project:
+    value: golicenser
+    regexp: go-?licenser
+year-mode: git-range
+shallow-policy: preserve
+timezone: UTC
+comment-style: block
+exclude:
+  - "**/testdata/**"
+max-concurrent: 4
+max-processes: 2
+legacy:
+  - template: Apache-2.0
+    author: Old Author
+    severity: migrate
+migrations:
+  - from: MIT
+    to: Apache-2.0
+    carry: [year]
+`,
+			check: func(t *testing.T, cf *configFile) {
+				t.Helper()
+				if cf.Template != "MIT" {
+					t.Errorf("Template = %q, want MIT", cf.Template)
+				}
+				if v := cf.Variables["project"]; v.Value != "golicenser" || v.Regexp != "go-?licenser" {
+					t.Errorf("Variables[project] = %+v", v)
+				}
+				if cf.YearMode == nil || *cf.YearMode != golicenser.YearModeGitRange {
+					t.Errorf("YearMode = %v, want %v", cf.YearMode, golicenser.YearModeGitRange)
+				}
+				if cf.Timezone != "UTC" {
+					t.Errorf("Timezone = %q, want UTC", cf.Timezone)
+				}
+				if cf.ShallowPolicy == nil || *cf.ShallowPolicy != golicenser.ShallowPreserve {
+					t.Errorf("ShallowPolicy = %v, want %v", cf.ShallowPolicy, golicenser.ShallowPreserve)
+				}
+				if cf.CommentStyle == nil || *cf.CommentStyle != golicenser.CommentStyleBlock {
+					t.Errorf("CommentStyle = %v, want %v", cf.CommentStyle, golicenser.CommentStyleBlock)
+				}
+				if cf.MaxConcurrent == nil || *cf.MaxConcurrent != 4 {
+					t.Errorf("MaxConcurrent = %v, want 4", cf.MaxConcurrent)
+				}
+				if cf.MaxProcesses == nil || *cf.MaxProcesses != 2 {
+					t.Errorf("MaxProcesses = %v, want 2", cf.MaxProcesses)
+				}
+				legacy, err := cf.legacy(cf.Legacy)
+				if err != nil {
+					t.Fatalf("legacy() err = %v", err)
+				}
+				if len(legacy) != 1 || legacy[0].Template != golicenser.LicenseApache2 ||
+					legacy[0].Author != "Old Author" || legacy[0].Severity != golicenser.LegacyMigrate {
+					t.Errorf("legacy() = %+v", legacy)
+				}
+				migrations, err := cf.migrations(cf.Migrations)
+				if err != nil {
+					t.Fatalf("migrations() err = %v", err)
+				}
+				if len(migrations) != 1 || migrations[0].From != golicenser.LicenseMIT ||
+					migrations[0].To != golicenser.LicenseApache2 || len(migrations[0].Carry) != 1 {
+					t.Errorf("migrations() = %+v", migrations)
+				}
+			},
+		},
+		{
+			name: "history",
+			content: `version: 1
+history: json
+history-file: build/history.json
+git-date: author
+ignore-revs-file: .git-blame-ignore-revs
+ignore-revs: [abc1234]
+ignore-messages: ["^chore: update license headers"]
+`,
+			check: func(t *testing.T, cf *configFile) {
+				t.Helper()
+				opts := historyConfig(cf, nil)
+				if opts.Source != golicenser.HistorySourceJSON {
+					t.Errorf("history source = %q, want %q", opts.Source, golicenser.HistorySourceJSON)
+				}
+				dir := filepath.Dir(cf.path)
+				if want := filepath.Join(dir, "build", "history.json"); opts.File != want {
+					t.Errorf("history file = %q, want %q", opts.File, want)
+				}
+				if want := filepath.Join(dir, ".git-blame-ignore-revs"); opts.Git.IgnoreRevsFile != want {
+					t.Errorf("ignore revs file = %q, want %q", opts.Git.IgnoreRevsFile, want)
+				}
+				if opts.Git.Date != golicenser.GitDateAuthor {
+					t.Errorf("git date = %q, want %q", opts.Git.Date, golicenser.GitDateAuthor)
+				}
+				if len(opts.Git.IgnoreRevs) != 1 || len(opts.Git.IgnoreMessages) != 1 {
+					t.Errorf("git history opts = %+v", opts.Git)
+				}
+			},
+		},
+		{
+			name:    "year policy",
+			content: "version: 1\nyear-mode: start=preserve|git-created end=git-modified format=compact-list\n",
+			check: func(t *testing.T, cf *configFile) {
+				t.Helper()
+				want := "start=preserve|git-created end=git-modified format=compact-list"
+				if cf.YearMode == nil || cf.YearMode.String() != want {
+					t.Errorf("YearMode = %v, want %s", cf.YearMode, want)
+				}
+			},
+		},
+		{
+			name:    "missing version",
+			content: "author: test\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			content: "version: 1\nauthr: test\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid year mode",
+			content: "version: 1\nyear-mode: sometimes\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid legacy severity",
+			content: "version: 1\nlegacy:\n  - template: MIT\n    severity: loud\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), ".golicenser.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			cf, err := loadConfigFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadConfigFile() err = %v, want err %v", err, tt.wantErr)
+			}
+			if tt.check != nil && cf != nil {
+				tt.check(t, cf)
+			}
+		})
+	}
+}
+
+func TestConfigFileRules(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "bsd.txt"), []byte("BSD {{.author}}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cf := &configFile{
+		path: filepath.Join(dir, ".golicenser.yaml"),
+		Rules: []configRule{
+			{
+				Paths:        []string{"**/third_party/**"},
+				configHeader: configHeader{TemplateFile: "bsd.txt", Author: "Vendor"},
+			},
+			{
+				Paths:        []string{"**/pkg/**"},
+				configHeader: configHeader{Template: "Apache-2.0"},
+			},
+			{
+				Paths:        []string{"**/internal/**"},
+				configHeader: configHeader{SPDX: "MIT"},
+			},
+			{
+				Paths: []string{"**/legacy/**"},
+				configHeader: configHeader{
+					Template:   "MIT",
+					Legacy:     []configLegacy{{Template: "BSD"}},
+					Migrations: []configMigration{{From: "BSD", To: "MIT"}},
+				},
+			},
+		},
+	}
+	base := golicenser.HeaderOpts{
+		Template:   "base",
+		SPDX:       "Apache-2.0",
+		Author:     "Base",
+		YearMode:   golicenser.YearModeGitRange,
+		Legacy:     []golicenser.LegacyHeader{{Template: golicenser.LicenseApache2}},
+		Migrations: []golicenser.Migration{{From: golicenser.LicenseApache2, To: golicenser.LicenseMIT}},
+	}
+
+	rules, err := cf.rules(base)
+	if err != nil {
+		t.Fatalf("rules() err = %v", err)
+	}
+	if len(rules) != 4 {
+		t.Fatalf("rules() len = %d, want 4", len(rules))
+	}
+	if h := rules[0].Header; h.Template != "BSD {{.author}}" || h.Author != "Vendor" || h.SPDX != "" {
+		t.Errorf("rules[0].Header = %+v", h)
+	}
+	if h := rules[1].Header; h.Template != golicenser.LicenseApache2 || h.Author != "Base" ||
+		h.YearMode != golicenser.YearModeGitRange {
+		t.Errorf("rules[1].Header = %+v", h)
+	}
+	if h := rules[2].Header; h.Template != "" || h.SPDX != "MIT" {
+		t.Errorf("rules[2].Header = %+v", h)
+	}
+	// Unset legacy headers and migrations are inherited from base.
+	for _, r := range rules[:3] {
+		if h := r.Header; len(h.Legacy) != 1 || h.Legacy[0].Template != golicenser.LicenseApache2 ||
+			len(h.Migrations) != 1 || h.Migrations[0].From != golicenser.LicenseApache2 {
+			t.Errorf("rules(%v).Header legacy = %+v, migrations = %+v", r.Paths, h.Legacy, h.Migrations)
+		}
+	}
+	if h := rules[3].Header; len(h.Legacy) != 1 || h.Legacy[0].Template != "BSD" ||
+		len(h.Migrations) != 1 || h.Migrations[0].From != "BSD" {
+		t.Errorf("rules[3].Header legacy = %+v, migrations = %+v", h.Legacy, h.Migrations)
+	}
+
+	cf.Rules = append(cf.Rules, configRule{Paths: []string{"**"}})
+	if _, err = cf.rules(base); err == nil {
+		t.Errorf("rules() with missing template err = nil, want error")
+	}
+}
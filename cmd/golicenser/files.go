@@ -0,0 +1,228 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/joshuasing/golicenser"
+)
+
+// skipDirs are the names of directories that are not walked.
+var skipDirs = map[string]bool{
+	".git":         true,
+	".hg":          true,
+	".svn":         true,
+	"node_modules": true,
+	"testdata":     true,
+	"vendor":       true,
+}
+
+// walkFiles calls fn for each file matching the patterns. Patterns ending in
+// "/..." match all files in the directory and its subdirectories, other
+// directories match only the files directly inside them.
+func walkFiles(patterns []string, fn func(path string) error) error {
+	for _, pattern := range patterns {
+		root, recursive := strings.CutSuffix(pattern, "...")
+		if recursive {
+			root = filepath.Clean(root)
+		}
+		root, err := filepath.Abs(root)
+		if err != nil {
+			return err
+		}
+
+		info, err := os.Stat(root)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			if err = fn(root); err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && (!recursive || skipDirs[d.Name()]) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			return fn(path)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lintFiles checks the license headers of files using the linter, calling fn
+// with the issues found for each file. Files are processed concurrently.
+func lintFiles(l *golicenser.Linter, patterns []string, maxConcurrent int, include func(path string) bool, fn func(path string, issues []golicenser.Issue) error) error {
+	var errg errgroup.Group
+	errg.SetLimit(max(maxConcurrent, 1))
+
+	err := walkFiles(patterns, func(path string) error {
+		if !include(path) {
+			return nil
+		}
+		errg.Go(func() error {
+			//nolint:gosec // Reading user-defined file.
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			issues, err := l.Check(path, src)
+			if err != nil {
+				return err
+			}
+			return fn(path, issues)
+		})
+		return nil
+	})
+	if werr := errg.Wait(); err == nil {
+		err = werr
+	}
+	return err
+}
+
+// writeFixed writes the fixed file content, preserving the file mode.
+func writeFixed(path string, fixed []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, fixed, info.Mode().Perm())
+}
+
+// printIssues prints the issues sorted by position.
+func printIssues(issues []golicenser.Issue) {
+	slices.SortFunc(issues, func(a, b golicenser.Issue) int {
+		if c := strings.Compare(a.Pos.Filename, b.Pos.Filename); c != 0 {
+			return c
+		}
+		return a.Pos.Line - b.Pos.Line
+	})
+	for _, issue := range issues {
+		fmt.Fprintln(os.Stderr, issue)
+	}
+}
+
+// runFiles runs the files command, which checks the license headers of
+// non-Go files.
+func runFiles(args []string) int {
+	flags := commandFlagSet("files", "[-flag] [path ...]",
+		"Checks license headers of non-Go files, such as shell scripts, Dockerfiles and YAML files.")
+	fix := flags.Bool("fix", false, "apply all suggested fixes")
+	_ = flags.Parse(args)
+
+	isNotGo := func(path string) bool {
+		return filepath.Ext(path) != ".go"
+	}
+	return lint(flags.Args(), *fix, isNotGo)
+}
+
+// lint checks the license headers of the files matching the patterns and the
+// include function, printing the issues found. If fix is true, suggested
+// fixes are written to the files, and the fixed issues are printed as fixed.
+// It returns the exit code, which is exitDiagnostics if any issue remains
+// unfixed.
+func lint(patterns []string, fix bool, include func(path string) bool) int {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fatal(err)
+	}
+	l, err := golicenser.NewLinter(cfg)
+	if err != nil {
+		return fatal(err)
+	}
+
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	var (
+		mu      sync.Mutex
+		issues  []golicenser.Issue
+		unfixed int
+	)
+	err = lintFiles(l, patterns, cfg.MaxConcurrent, include, func(path string, fileIssues []golicenser.Issue) error {
+		n := len(fileIssues)
+		if fix {
+			var err error
+			if n, err = fixIssues(path, fileIssues); err != nil {
+				return err
+			}
+		}
+		mu.Lock()
+		issues = append(issues, fileIssues...)
+		unfixed += n
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return fatal(err)
+	}
+
+	printIssues(issues)
+	printMetrics()
+	if unfixed > 0 {
+		return exitDiagnostics
+	}
+	return 0
+}
+
+// fixIssues writes the first suggested fix for a file, prefixing the message
+// of the fixed issue with "fixed: ". Each suggested fix contains the whole
+// file, so any other fixes are left for the next run. It returns the number
+// of issues left unfixed.
+func fixIssues(path string, issues []golicenser.Issue) (int, error) {
+	unfixed := len(issues)
+	for i, issue := range issues {
+		if issue.Fixed == nil {
+			continue
+		}
+		if err := writeFixed(path, issue.Fixed); err != nil {
+			return 0, err
+		}
+		issues[i].Message = "fixed: " + issue.Message
+		unfixed--
+		break
+	}
+	return unfixed, nil
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/joshuasing/golicenser"
+)
+
+func TestWalkFiles(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	files := []string{
+		"Makefile",
+		"sub/run.sh",
+		"sub/deep/config.yaml",
+		".git/config",
+		"vendor/lib/lib.sh",
+		"testdata/data.yaml",
+	}
+	for _, f := range files {
+		p := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{
+			name:     "recursive",
+			patterns: []string{root + "/..."},
+			want:     []string{"Makefile", "sub/deep/config.yaml", "sub/run.sh"},
+		},
+		{
+			name:     "directory",
+			patterns: []string{filepath.Join(root, "sub")},
+			want:     []string{"sub/run.sh"},
+		},
+		{
+			name:     "file",
+			patterns: []string{filepath.Join(root, "vendor/lib/lib.sh")},
+			want:     []string{"vendor/lib/lib.sh"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			err := walkFiles(tt.patterns, func(path string) error {
+				rel, err := filepath.Rel(root, path)
+				got = append(got, filepath.ToSlash(rel))
+				return err
+			})
+			if err != nil {
+				t.Fatalf("walkFiles() err = %v", err)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("walkFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintFilesGo(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	files := map[string]string{
+		"a.go":          "package a\n",
+		"b.go":          "// Copyright (c) 2025 Test\n\npackage a\n",
+		"gen.go":        "// Code generated by test. DO NOT EDIT.\n\npackage a\n",
+		"sub/c_test.go": "package sub\n",
+		"run.sh":        "echo\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l, err := golicenser.NewLinter(golicenser.Config{
+		Header: golicenser.HeaderOpts{
+			Template: "Copyright (c) {{.year}} {{.author}}",
+			Author:   "Test",
+			YearMode: golicenser.YearModePreserve,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewLinter() err = %v", err)
+	}
+
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+	isGo := func(path string) bool {
+		return filepath.Ext(path) == ".go"
+	}
+	err = lintFiles(l, []string{root + "/..."}, 4, isGo, func(path string, issues []golicenser.Issue) error {
+		if len(issues) == 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		mu.Lock()
+		got = append(got, filepath.ToSlash(rel))
+		mu.Unlock()
+		return err
+	})
+	if err != nil {
+		t.Fatalf("lintFiles() err = %v", err)
+	}
+	slices.Sort(got)
+	if want := []string{"a.go", "sub/c_test.go"}; !slices.Equal(got, want) {
+		t.Errorf("lintFiles() issues in %v, want %v", got, want)
+	}
+}
+
+func TestFixIssues(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		issues      []golicenser.Issue
+		wantUnfixed int
+		wantContent string
+		wantFixed   []bool
+	}{
+		{
+			name:        "fixed",
+			issues:      []golicenser.Issue{{Message: "missing license header", Fixed: []byte("fixed\n")}},
+			wantUnfixed: 0,
+			wantContent: "fixed\n",
+			wantFixed:   []bool{true},
+		},
+		{
+			name:        "no suggested fix",
+			issues:      []golicenser.Issue{{Message: "shallow clone"}},
+			wantUnfixed: 1,
+			wantContent: "original\n",
+			wantFixed:   []bool{false},
+		},
+		{
+			name: "mixed",
+			issues: []golicenser.Issue{
+				{Message: "legacy license header"},
+				{Message: "invalid license header", Fixed: []byte("first\n")},
+				{Message: "invalid license header", Fixed: []byte("second\n")},
+			},
+			wantUnfixed: 2,
+			wantContent: "first\n",
+			wantFixed:   []bool{false, true, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "run.sh")
+			if err := os.WriteFile(path, []byte("original\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			unfixed, err := fixIssues(path, tt.issues)
+			if err != nil {
+				t.Fatalf("fixIssues() err = %v", err)
+			}
+			if unfixed != tt.wantUnfixed {
+				t.Errorf("fixIssues() = %d, want %d", unfixed, tt.wantUnfixed)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.wantContent {
+				t.Errorf("file content = %q, want %q", content, tt.wantContent)
+			}
+			for i, issue := range tt.issues {
+				if got := strings.HasPrefix(issue.Message, "fixed: "); got != tt.wantFixed[i] {
+					t.Errorf("issues[%d].Message = %q, want fixed %v", i, issue.Message, tt.wantFixed[i])
+				}
+			}
+		})
+	}
+}
@@ -23,10 +23,15 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/singlechecker"
@@ -41,8 +46,10 @@ var flagSet flag.FlagSet
 var DefaultMaxConcurrent = runtime.GOMAXPROCS(0) * 2
 
 var (
+	configPath             string
 	template               string
 	templateFile           string
+	spdx                   string
 	matcher                string
 	matcherFile            string
 	matcherEscape          bool
@@ -55,12 +62,30 @@ var (
 	exclude                string
 	maxConcurrent          int
 	copyrightHeaderMatcher string
+	strict                 bool
+	historySource          string
+	historyFile            string
+	ignoreRevsFile         string
+	ignoreMessage          string
+	shallowPolicyStr       string
+	gitDate                string
+	timezone               string
+	now                    string
+	since                  string
+	staged                 bool
+	cacheDir               string
+	maxProcesses           int
+	showMetrics            bool
 )
 
 func init() {
+	flagSet.StringVar(&configPath, "config", "",
+		"Configuration file (default: search for .golicenser.yaml in the current and parent directories)")
 	flagSet.StringVar(&template, "tmpl", "", "License header template")
 	flagSet.StringVar(&templateFile, "tmpl-file", "license_header.txt",
 		"License header template file")
+	flagSet.StringVar(&spdx, "spdx", "",
+		"SPDX license expression for SPDX short-form headers (e.g. Apache-2.0)")
 	flagSet.StringVar(&matcher, "matcher", "",
 		"License header matcher (This is template, when executed it must become valid regexp)")
 	flagSet.StringVar(&matcherFile, "matcher-file", "",
@@ -74,112 +99,423 @@ func init() {
 	flagSet.StringVar(&variableRegexps, "var-regexp", "",
 		"Template variable regexps (e.g. 'a=(Hello|World),b=(?i)test'")
 	flagSet.StringVar(&yearModeStr, "year-mode", golicenser.YearMode(0).String(),
-		"Year formatting mode (preserve, preserve-this-year-range, preserve-modified-range, this-year, last-modified, git-range, git-modified-years)")
+		"Year formatting mode (preserve, preserve-this-year-range, preserve-modified-range, this-year, last-modified, git-range, git-modified-list) or year policy (e.g. 'start=preserve|git-created end=git-modified format=compact-list')")
 	flagSet.StringVar(&commentStyleStr, "comment-style", golicenser.CommentStyle(0).String(),
 		"Comment style (line, block)")
 	flagSet.StringVar(&exclude, "exclude", "",
 		"Paths to exclude (doublestar or r!-prefixed regexp, comma-separated)")
 	flagSet.IntVar(&maxConcurrent, "max-concurrent", DefaultMaxConcurrent,
 		"Maximum concurrent processes to use when processing files")
+	flagSet.IntVar(&maxProcesses, "max-processes", runtime.GOMAXPROCS(0),
+		"Maximum concurrent external processes (e.g. git) across all packages")
+	flagSet.BoolVar(&showMetrics, "metrics", false,
+		"Print metrics (git calls, history lookups, cache hits and time spent) when the check, files, migrate or precommit command finishes (not supported when running as an analyzer)")
 	flagSet.StringVar(&copyrightHeaderMatcher, "copyright-header-matcher", golicenser.DefaultCopyrightHeaderMatcher,
 		"Copyright header matcher regexp (used to detect existence of any copyright header)")
+	flagSet.BoolVar(&strict, "strict", false,
+		"Report copyright headers that do not match the license header")
+	flagSet.StringVar(&historySource, "history", "",
+		"File history source used by year modes (git, fs, json) (default: json if -history-file is set, otherwise git)")
+	flagSet.StringVar(&historyFile, "history-file", "",
+		"JSON history file used by the json history source")
+	flagSet.StringVar(&ignoreRevsFile, "ignore-revs-file", "",
+		"File of Git commits to ignore when calculating years (e.g. .git-blame-ignore-revs)")
+	flagSet.StringVar(&ignoreMessage, "ignore-message", "",
+		"Regexp matching subjects of Git commits to ignore when calculating years")
+	flagSet.StringVar(&shallowPolicyStr, "shallow-policy", golicenser.ShallowPolicy(0).String(),
+		"Year policy when Git history is truncated by a shallow clone (trust-existing, preserve, fail, ignore)")
+	flagSet.StringVar(&gitDate, "git-date", golicenser.GitDateCommitter,
+		"Git commit date used for years (committer, author)")
+	flagSet.StringVar(&timezone, "timezone", "",
+		"Time zone used for year boundaries (e.g. UTC) (default: local time zone)")
+	flagSet.StringVar(&cacheDir, "cache-dir", "",
+		"Directory used to cache results of the check, files and precommit commands, or empty to disable caching (default: golicenser in the user cache directory)")
+	flagSet.StringVar(&since, "since", "",
+		"Only update years of files changed since the merge base of this Git ref (e.g. origin/main)")
+	flagSet.BoolVar(&staged, "staged", false,
+		"Only update years of files with staged changes")
+	flagSet.StringVar(&now, "now", "",
+		"Current time used for years, as RFC 3339 or YYYY-MM-DD (default: $SOURCE_DATE_EPOCH or the current time)")
 }
 
-// TODO(joshuasing): There has to be a better way of doing this...
+// commandFlags is the flag set of the running command, if any.
+var commandFlags *flag.FlagSet
 
-var analyzer = &analysis.Analyzer{
-	Name: "golicenser",
-	Doc:  "manages license headers",
-	URL:  "https://github.com/joshuasing/golicenser",
-	Run: func(pass *analysis.Pass) (any, error) {
-		var err error
-		if template == "" {
-			//nolint:gosec // Reading user-defined file.
-			b, err := os.ReadFile(templateFile)
-			if err != nil {
-				log.Fatal("read template file: ", err)
-			}
-			template = string(b)
-		} else {
-			if tm, ok := golicenser.TemplateBySPDX(template); ok {
-				template = tm
+// flagsSet returns the names of the flags that have been explicitly set.
+func flagsSet() map[string]bool {
+	set := make(map[string]bool)
+	visit := func(f *flag.Flag) {
+		set[f.Name] = true
+	}
+	// The analysis driver registers the analyzer flags on the global flag set.
+	flag.Visit(visit)
+	flagSet.Visit(visit)
+	if commandFlags != nil {
+		commandFlags.Visit(visit)
+	}
+	return set
+}
+
+// exitDiagnostics is the exit code used when diagnostics are reported. This
+// matches the exit code used by the analysis driver.
+const exitDiagnostics = 3
+
+// commands are the golicenser subcommands. If no subcommand is given,
+// golicenser runs as an analysis driver.
+var commands = map[string]func(args []string) int{
+	"check":     runCheck,
+	"files":     runFiles,
+	"history":   runHistory,
+	"migrate":   runMigrate,
+	"precommit": runPrecommit,
+	"templates": runTemplates,
+}
+
+// commandFlagSet creates the flag set for a command, including the golicenser
+// configuration flags.
+func commandFlagSet(name, usage, doc string) *flag.FlagSet {
+	fs := flag.NewFlagSet("golicenser "+name, flag.ExitOnError)
+	flagSet.VisitAll(func(f *flag.Flag) {
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "%s\n\nUsage: golicenser %s %s\n\nFlags:\n", doc, name, usage)
+		fs.PrintDefaults()
+	}
+	commandFlags = fs
+	return fs
+}
+
+// fatal prints the error and returns the exit code for errors.
+func fatal(err error) int {
+	fmt.Fprintln(os.Stderr, "golicenser:", err)
+	return 1
+}
+
+// loadConfig creates the golicenser configuration from the configuration file
+// (if any) and flags. Flags that have been explicitly set take precedence over
+// values from the configuration file.
+func loadConfig() (golicenser.Config, error) {
+	set := flagsSet()
+
+	cf, err := openConfigFile()
+	if err != nil {
+		return golicenser.Config{}, err
+	}
+
+	// SPDX short-form header
+	spdxExpr := cf.SPDX
+	if set["spdx"] {
+		spdxExpr = spdx
+	}
+
+	// Template
+	tmpl, tmplFile := cf.Template, cf.resolve(cf.TemplateFile)
+	switch {
+	case set["tmpl"]:
+		tmpl, tmplFile = template, ""
+	case set["tmpl-file"]:
+		tmpl, tmplFile = "", templateFile
+	case tmpl == "" && tmplFile == "" && spdxExpr == "":
+		tmplFile = templateFile
+	}
+	if tmpl, err = readTemplate(tmpl, tmplFile); err != nil {
+		return golicenser.Config{}, fmt.Errorf("read template file: %w", err)
+	}
+
+	// Matcher
+	match, matchFile := cf.Matcher, cf.resolve(cf.MatcherFile)
+	switch {
+	case set["matcher"]:
+		match, matchFile = matcher, ""
+	case set["matcher-file"]:
+		match, matchFile = "", matcherFile
+	}
+	if match, err = readTemplate(match, matchFile); err != nil {
+		return golicenser.Config{}, fmt.Errorf("read matcher file: %w", err)
+	}
+
+	cfg := golicenser.Config{
+		Header: golicenser.HeaderOpts{
+			Template:     tmpl,
+			SPDX:         spdxExpr,
+			Matcher:      match,
+			Author:       cf.Author,
+			AuthorRegexp: cf.AuthorRegexp,
+			Variables:    make(map[string]*golicenser.Var),
+		},
+		Exclude:                cf.Exclude,
+		MaxConcurrent:          maxConcurrent,
+		CopyrightHeaderMatcher: cf.CopyrightHeaderMatcher,
+	}
+	if cf.MatcherEscape != nil && !set["matcher-escape"] {
+		cfg.Header.MatcherEscape = *cf.MatcherEscape
+	} else {
+		cfg.Header.MatcherEscape = matcherEscape
+	}
+	if set["author"] || cfg.Header.Author == "" {
+		cfg.Header.Author = author
+	}
+	if set["author-regexp"] || cfg.Header.AuthorRegexp == "" {
+		cfg.Header.AuthorRegexp = authorRegexp
+	}
+	if set["exclude"] || cfg.Exclude == nil {
+		cfg.Exclude = strings.Split(exclude, ",")
+	}
+	if cf.MaxConcurrent != nil && !set["max-concurrent"] {
+		cfg.MaxConcurrent = *cf.MaxConcurrent
+	}
+	cfg.MaxProcesses = maxProcesses
+	if cf.MaxProcesses != nil && !set["max-processes"] {
+		cfg.MaxProcesses = *cf.MaxProcesses
+	}
+	if set["copyright-header-matcher"] || cfg.CopyrightHeaderMatcher == "" {
+		cfg.CopyrightHeaderMatcher = copyrightHeaderMatcher
+	}
+	if cf.Strict != nil && !set["strict"] {
+		cfg.Strict = *cf.Strict
+	} else {
+		cfg.Strict = strict
+	}
+
+	// History
+	history := historyConfig(cf, set)
+	cfg.HistorySource, cfg.HistoryFile = history.Source, history.File
+	cfg.GitDate = history.Git.Date
+	cfg.IgnoreRevsFile = history.Git.IgnoreRevsFile
+	cfg.IgnoreRevs = history.Git.IgnoreRevs
+	cfg.IgnoreMessages = history.Git.IgnoreMessages
+
+	// Variables
+	for name, v := range cf.Variables {
+		cfg.Header.Variables[name] = &golicenser.Var{Value: v.Value, Regexp: v.Regexp}
+	}
+	if variables != "" {
+		for _, v := range strings.Split(variables, ",") {
+			parts := strings.SplitN(v, "=", 2)
+			if len(parts) != 2 {
+				return golicenser.Config{}, fmt.Errorf("invalid variable: %s", v)
 			}
+			cfg.Header.Variables[parts[0]] = &golicenser.Var{Value: parts[1]}
 		}
-		if matcher == "" && matcherFile != "" {
-			//nolint:gosec // Reading user-defined file.
-			b, err := os.ReadFile(matcherFile)
-			if err != nil {
-				log.Fatal("read matcher file: ", err)
+	}
+	if variableRegexps != "" {
+		for _, v := range strings.Split(variableRegexps, ",") {
+			parts := strings.SplitN(v, "=", 2)
+			if len(parts) != 2 {
+				return golicenser.Config{}, fmt.Errorf("invalid variable: %s", v)
 			}
-			matcher = string(b)
-		} else {
-			if tm, ok := golicenser.TemplateBySPDX(matcher); ok {
-				matcher = tm
+			va, ok := cfg.Header.Variables[parts[0]]
+			if !ok {
+				return golicenser.Config{}, fmt.Errorf("regexp for non-existent variable: %s", v)
 			}
+			va.Regexp = parts[1]
 		}
+	}
 
-		// Parse variables
-		vars := make(map[string]*golicenser.Var)
-		if variables != "" {
-			for _, v := range strings.Split(variables, ",") {
-				parts := strings.SplitN(v, "=", 2)
-				if len(parts) != 2 {
-					log.Fatal("invalid variable: ", v)
-				}
-				vars[parts[0]] = &golicenser.Var{Value: parts[1]}
-			}
+	// Year mode
+	if cf.YearMode != nil && !set["year-mode"] {
+		cfg.Header.YearMode = *cf.YearMode
+	} else {
+		if cfg.Header.YearMode, err = golicenser.ParseYearMode(yearModeStr); err != nil {
+			return golicenser.Config{}, fmt.Errorf("parse year mode: %w", err)
 		}
-		if variableRegexps != "" {
-			for _, v := range strings.Split(variableRegexps, ",") {
-				parts := strings.SplitN(v, "=", 2)
-				if len(parts) != 2 {
-					log.Fatal("invalid variable: ", v)
-				}
-				va, ok := vars[parts[0]]
-				if !ok {
-					log.Fatal("regexp for non-existent variable: ", v)
-				}
-				va.Regexp = parts[1]
-			}
+	}
+
+	// Shallow policy
+	if cf.ShallowPolicy != nil && !set["shallow-policy"] {
+		cfg.Header.ShallowPolicy = *cf.ShallowPolicy
+	} else {
+		if cfg.Header.ShallowPolicy, err = golicenser.ParseShallowPolicy(shallowPolicyStr); err != nil {
+			return golicenser.Config{}, fmt.Errorf("parse shallow policy: %w", err)
 		}
+	}
 
-		// Parse year mode
-		var yearMode golicenser.YearMode
-		if yearMode, err = golicenser.ParseYearMode(yearModeStr); err != nil {
-			log.Fatal("parse year mode: ", err)
+	// Change scope
+	cfg.Since, cfg.Staged = since, staged
+
+	// Cache
+	cfg.CacheDir = cacheDir
+	if !set["cache-dir"] {
+		if dir, err := os.UserCacheDir(); err == nil {
+			cfg.CacheDir = filepath.Join(dir, "golicenser")
 		}
+	}
+
+	// Timezone
+	cfg.Header.Timezone = cf.Timezone
+	if set["timezone"] {
+		cfg.Header.Timezone = timezone
+	}
 
-		// Parse comment style
-		var commentStyle golicenser.CommentStyle
-		if commentStyle, err = golicenser.ParseCommentStyle(commentStyleStr); err != nil {
-			log.Fatal("parse comment style: ", err)
+	// Clock
+	if cfg.Clock, err = parseClock(now, os.Getenv("SOURCE_DATE_EPOCH"), cfg.Header.Timezone); err != nil {
+		return golicenser.Config{}, err
+	}
+
+	// Comment style
+	if cf.CommentStyle != nil && !set["comment-style"] {
+		cfg.Header.CommentStyle = *cf.CommentStyle
+	} else {
+		if cfg.Header.CommentStyle, err = golicenser.ParseCommentStyle(commentStyleStr); err != nil {
+			return golicenser.Config{}, fmt.Errorf("parse comment style: %w", err)
 		}
+	}
 
-		a, err := golicenser.NewAnalyzer(golicenser.Config{
-			Header: golicenser.HeaderOpts{
-				Template:      template,
-				Matcher:       matcher,
-				MatcherEscape: matcherEscape,
-				Author:        author,
-				AuthorRegexp:  authorRegexp,
-				Variables:     vars,
-				YearMode:      yearMode,
-				CommentStyle:  commentStyle,
-			},
-			Exclude:                strings.Split(exclude, ","),
-			MaxConcurrent:          maxConcurrent,
-			CopyrightHeaderMatcher: copyrightHeaderMatcher,
-		})
+	// Legacy headers
+	if cfg.Header.Legacy, err = cf.legacy(cf.Legacy); err != nil {
+		return golicenser.Config{}, fmt.Errorf("load legacy headers: %w", err)
+	}
+
+	// Migrations
+	if cfg.Header.Migrations, err = cf.migrations(cf.Migrations); err != nil {
+		return golicenser.Config{}, fmt.Errorf("load migrations: %w", err)
+	}
+
+	// Rules
+	if cfg.Rules, err = cf.rules(cfg.Header); err != nil {
+		return golicenser.Config{}, fmt.Errorf("load rules: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// printMetrics prints the golicenser metrics, if enabled with -metrics.
+func printMetrics() {
+	if !showMetrics {
+		return
+	}
+	m := golicenser.ReadMetrics()
+	fmt.Fprintf(os.Stderr, "golicenser: %d git calls (%s, %s waiting), %d history lookups (%d memoized), %d cache hits, %d cache misses\n",
+		m.GitCalls, m.GitTime.Round(time.Millisecond), m.GitWaitTime.Round(time.Millisecond),
+		m.HistoryLookups, m.HistoryHits, m.CacheHits, m.CacheMisses)
+}
+
+// parseClock returns a fixed clock from the -now flag or the SOURCE_DATE_EPOCH
+// environment variable (see https://reproducible-builds.org/specs/source-date-epoch/),
+// in order of precedence. If neither is set, nil is returned and the wall clock
+// is used. A -now date without a time is midnight in the timezone, or UTC if
+// the timezone is empty, so that the date is the same in the timezone.
+func parseClock(now, sourceDateEpoch, timezone string) (func() time.Time, error) {
+	var t time.Time
+	switch {
+	case now != "":
+		var err error
+		if t, err = time.Parse(time.RFC3339, now); err != nil {
+			location := time.UTC
+			if timezone != "" {
+				if location, err = time.LoadLocation(timezone); err != nil {
+					return nil, fmt.Errorf("invalid timezone: %w", err)
+				}
+			}
+			if t, err = time.ParseInLocation(time.DateOnly, now, location); err != nil {
+				return nil, fmt.Errorf("invalid -now: %q (want RFC 3339 or YYYY-MM-DD)", now)
+			}
+		}
+	case sourceDateEpoch != "":
+		sec, err := strconv.ParseInt(sourceDateEpoch, 10, 64)
 		if err != nil {
-			log.Fatal(err)
+			return nil, fmt.Errorf("invalid SOURCE_DATE_EPOCH: %q", sourceDateEpoch)
 		}
+		t = time.Unix(sec, 0).UTC()
+	default:
+		return nil, nil
+	}
+	return func() time.Time { return t }, nil
+}
 
-		return a.Run(pass)
+// openConfigFile loads the configuration file from the -config flag, or the
+// configuration file found in the current or parent directories. An empty
+// configuration is returned if there is no configuration file.
+func openConfigFile() (*configFile, error) {
+	path := configPath
+	if path == "" {
+		var err error
+		if path, err = findConfigFile("."); err != nil {
+			return nil, fmt.Errorf("find config file: %w", err)
+		}
+	}
+	if path == "" {
+		return &configFile{Version: configVersion}, nil
+	}
+	cf, err := loadConfigFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load config file: %w", err)
+	}
+	return cf, nil
+}
+
+// historyConfig returns the history options from the configuration file and
+// flags.
+func historyConfig(cf *configFile, set map[string]bool) golicenser.HistoryOpts {
+	opts := golicenser.HistoryOpts{
+		Source: cf.History,
+		File:   cf.resolve(cf.HistoryFile),
+		Git: golicenser.GitHistoryOpts{
+			Date:           cf.GitDate,
+			IgnoreRevsFile: cf.resolve(cf.IgnoreRevsFile),
+			IgnoreRevs:     cf.IgnoreRevs,
+			IgnoreMessages: cf.IgnoreMessages,
+		},
+	}
+	if set["history"] {
+		opts.Source = historySource
+	}
+	if set["history-file"] {
+		opts.File = historyFile
+	}
+	if set["git-date"] {
+		opts.Git.Date = gitDate
+	}
+	if set["ignore-revs-file"] {
+		opts.Git.IgnoreRevsFile = ignoreRevsFile
+	}
+	if set["ignore-message"] {
+		opts.Git.IgnoreMessages = []string{ignoreMessage}
+	}
+	return opts
+}
+
+var (
+	analyzerOnce sync.Once
+	analyzerRun  func(pass *analysis.Pass) (any, error)
+)
+
+var analyzer = &analysis.Analyzer{
+	Name: "golicenser",
+	Doc:  "manages license headers",
+	URL:  "https://github.com/joshuasing/golicenser",
+	Run: func(pass *analysis.Pass) (any, error) {
+		// The configuration can only be loaded once flags have been parsed,
+		// which happens when the analysis driver starts.
+		analyzerOnce.Do(func() {
+			cfg, err := loadConfig()
+			if err != nil {
+				log.Fatal(err)
+			}
+			a, err := golicenser.NewAnalyzer(cfg)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if showMetrics {
+				// The analysis driver exits without returning to main, so
+				// metrics cannot be printed once all packages are checked.
+				log.Print("-metrics is not supported when running as an analyzer, use the check command instead")
+			}
+			analyzerRun = a.Run
+		})
+		return analyzerRun(pass)
 	},
 	RunDespiteErrors: true,
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			os.Exit(cmd(os.Args[2:]))
+		}
+	}
+
 	analyzer.Flags = flagSet
 	singlechecker.Main(analyzer)
 }
@@ -0,0 +1,110 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseClock(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		now             string
+		sourceDateEpoch string
+		timezone        string
+		want            time.Time
+		wantErr         bool
+	}{
+		{
+			name: "wall clock",
+		},
+		{
+			name: "now RFC 3339",
+			now:  "2031-03-01T12:00:00Z",
+			want: time.Date(2031, time.March, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "now date",
+			now:  "2031-03-01",
+			want: time.Date(2031, time.March, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "now date in timezone west of UTC",
+			now:      "2026-01-01",
+			timezone: "America/New_York",
+			want:     time.Date(2026, time.January, 1, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "now RFC 3339 ignores timezone",
+			now:      "2031-03-01T12:00:00Z",
+			timezone: "America/New_York",
+			want:     time.Date(2031, time.March, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "invalid timezone",
+			now:      "2026-01-01",
+			timezone: "Nowhere/Invalid",
+			wantErr:  true,
+		},
+		{
+			name:            "source date epoch",
+			sourceDateEpoch: "1700000000",
+			want:            time.Unix(1700000000, 0).UTC(),
+		},
+		{
+			name:            "now takes precedence",
+			now:             "2031-03-01",
+			sourceDateEpoch: "1700000000",
+			want:            time.Date(2031, time.March, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "invalid now",
+			now:     "tomorrow",
+			wantErr: true,
+		},
+		{
+			name:            "invalid source date epoch",
+			sourceDateEpoch: "2031-03-01",
+			wantErr:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			clock, err := parseClock(tt.now, tt.sourceDateEpoch, tt.timezone)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseClock() err = %v, want err %v", err, tt.wantErr)
+			}
+			if tt.want.IsZero() {
+				if clock != nil {
+					t.Errorf("parseClock() = %v, want nil", clock())
+				}
+				return
+			}
+			if got := clock(); !got.Equal(tt.want) {
+				t.Errorf("parseClock() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
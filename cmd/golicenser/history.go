@@ -0,0 +1,87 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+
+	"github.com/joshuasing/golicenser"
+)
+
+// runHistory runs the history command, which writes a JSON history file for
+// use with the json history source.
+func runHistory(args []string) int {
+	flags := commandFlagSet("history", "[-flag] [path ...]",
+		"Writes the file history used by year modes to a JSON history file, for use in environments\n"+
+			"without access to the history (e.g. build sandboxes without a Git repository).")
+	output := flags.String("o", "",
+		"output file; paths are relative to its directory (default: stdout, with paths relative to the current directory)")
+	_ = flags.Parse(args)
+
+	cf, err := openConfigFile()
+	if err != nil {
+		return fatal(err)
+	}
+	history, err := golicenser.NewHistory(historyConfig(cf, flagsSet()))
+	if err != nil {
+		return fatal(err)
+	}
+
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	var files []string
+	err = walkFiles(patterns, func(path string) error {
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return fatal(err)
+	}
+
+	root, w := ".", os.Stdout
+	if *output != "" {
+		//nolint:gosec // Writing user-defined file.
+		f, err := os.Create(*output)
+		if err != nil {
+			return fatal(err)
+		}
+		root, w = filepath.Dir(*output), f
+	}
+
+	bw := bufio.NewWriter(w)
+	err = golicenser.WriteJSONHistory(bw, history, root, files)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if w != os.Stdout {
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		return fatal(err)
+	}
+	return 0
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"sync"
+
+	"github.com/joshuasing/golicenser"
+)
+
+// runMigrate runs the migrate command, which migrates license headers from
+// previous licenses.
+func runMigrate(args []string) int {
+	flags := commandFlagSet("migrate", "[-flag] [path ...]",
+		"Migrates license headers from previous licenses using the configured migrations.")
+	dryRun := flags.Bool("dry-run", false,
+		"report license headers requiring migration without updating files")
+	_ = flags.Parse(args)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fatal(err)
+	}
+	l, err := golicenser.NewLinter(cfg)
+	if err != nil {
+		return fatal(err)
+	}
+
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	var (
+		mu     sync.Mutex
+		issues []golicenser.Issue
+	)
+	all := func(string) bool { return true }
+	err = lintFiles(l, patterns, cfg.MaxConcurrent, all, func(path string, fileIssues []golicenser.Issue) error {
+		for _, issue := range fileIssues {
+			if issue.Category != golicenser.CategoryMigrate {
+				continue
+			}
+			if !*dryRun && issue.Fixed != nil {
+				if err := writeFixed(path, issue.Fixed); err != nil {
+					return err
+				}
+			}
+			mu.Lock()
+			issues = append(issues, issue)
+			mu.Unlock()
+		}
+		return nil
+	})
+	if err != nil {
+		return fatal(err)
+	}
+
+	printIssues(issues)
+	printMetrics()
+	if len(issues) > 0 && *dryRun {
+		return exitDiagnostics
+	}
+	return 0
+}
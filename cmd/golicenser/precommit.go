@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/joshuasing/golicenser"
+)
+
+// runPrecommit runs the precommit command, which checks the license headers
+// of the files staged for commit.
+func runPrecommit(args []string) int {
+	flags := commandFlagSet("precommit", "[-flag]",
+		"Checks license headers of the Go and non-Go files staged for commit, for use in a Git pre-commit hook.\n"+
+			"The staged content of each file is checked, not the working tree.")
+	_ = flags.Parse(args)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fatal(err)
+	}
+	l, err := golicenser.NewLinter(cfg)
+	if err != nil {
+		return fatal(err)
+	}
+
+	files, err := golicenser.ReadStagedFiles(".")
+	if err != nil {
+		return fatal(err)
+	}
+
+	// Check files concurrently, keeping the issues in staged file order.
+	results := make([][]golicenser.Issue, len(files))
+	var errg errgroup.Group
+	errg.SetLimit(max(cfg.MaxConcurrent, 1))
+	for i, f := range files {
+		errg.Go(func() error {
+			issues, err := l.Check(f.Path, f.Src)
+			results[i] = issues
+			return err
+		})
+	}
+	if err = errg.Wait(); err != nil {
+		return fatal(err)
+	}
+
+	wd, _ := os.Getwd()
+	var count int
+	for _, issues := range results {
+		for _, issue := range issues {
+			name := issue.Pos.Filename
+			if rel, err := filepath.Rel(wd, name); err == nil {
+				name = rel
+			}
+			fmt.Fprintf(os.Stderr, "%s:%d: %s\n", name, issue.Pos.Line, issue.Message)
+			count++
+		}
+	}
+	printMetrics()
+	if count > 0 {
+		fmt.Fprintf(os.Stderr, "golicenser: %d license header issue(s) in staged files\n", count)
+		return exitDiagnostics
+	}
+	return 0
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/joshuasing/golicenser"
+)
+
+const templatesDoc = `Lists and shows the built-in license header templates.
+
+Commands:
+  list         List the SPDX identifiers of the built-in templates
+  show <id>    Show the built-in template with the SPDX identifier`
+
+func runTemplates(args []string) int {
+	flags := flag.NewFlagSet("golicenser templates", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "%s\n\nUsage: golicenser templates list|show [id]\n", templatesDoc)
+	}
+	_ = flags.Parse(args)
+
+	switch flags.Arg(0) {
+	case "list":
+		listTemplates(os.Stdout)
+		return 0
+	case "show":
+		if flags.NArg() != 2 {
+			flags.Usage()
+			return 2
+		}
+		if err := showTemplate(os.Stdout, flags.Arg(1)); err != nil {
+			return fatal(err)
+		}
+		return 0
+	default:
+		flags.Usage()
+		return 2
+	}
+}
+
+// listTemplates writes the SPDX identifiers of the built-in templates.
+func listTemplates(w io.Writer) {
+	for _, name := range golicenser.Templates() {
+		fmt.Fprintln(w, name)
+	}
+}
+
+// showTemplate writes the built-in template with the SPDX identifier.
+func showTemplate(w io.Writer, spdx string) error {
+	tmpl, ok := golicenser.TemplateBySPDX(spdx)
+	if !ok {
+		return fmt.Errorf("unknown template %q (see golicenser templates list)", spdx)
+	}
+	_, err := fmt.Fprintln(w, tmpl)
+	return err
+}
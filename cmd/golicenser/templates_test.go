@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/joshuasing/golicenser"
+)
+
+func TestListTemplates(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	listTemplates(&b)
+	got := strings.Fields(b.String())
+	if want := golicenser.Templates(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("listTemplates() = %v, want %v", got, want)
+	}
+}
+
+func TestShowTemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spdx    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "MIT",
+			spdx: "MIT",
+			want: golicenser.LicenseMIT + "\n",
+		},
+		{
+			name: "BSD-3-Clause",
+			spdx: "BSD-3-Clause",
+			want: golicenser.LicenseBSD3Clause + "\n",
+		},
+		{
+			name:    "unknown",
+			spdx:    "Unknown-1.0",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var b bytes.Buffer
+			err := showTemplate(&b, tt.spdx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("showTemplate(%q) err = %v, want err %v", tt.spdx, err, tt.wantErr)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("showTemplate(%q) = %q, want %q", tt.spdx, got, tt.want)
+			}
+		})
+	}
+}
@@ -21,10 +21,20 @@
 package golicenser
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -32,98 +42,588 @@ import (
 // in a reproducible and reliable way in tests runs.
 var execCommand = exec.Command
 
-const gitISOTimeFormat = "2006-01-02 15:04:05 -0700"
+// gitCommitMarker marks the start of a commit in the git log output, and
+// gitFieldSeparator separates the fields of the commit line.
+const (
+	gitCommitMarker   = "\x1e"
+	gitFieldSeparator = "\x1f"
+)
 
-// gitModRange returns the creation time and last modification time of a file.
-func gitModRange(filename string) (time.Time, time.Time, error) {
-	// Retrieve file creation time from Git.
-	line, err := execCommand("git", "log", "--follow", "--find-renames=70%",
-		"--diff-filter=A", "--pretty=format:%cd", "--date=iso", "--", filename).CombinedOutput()
-	if err != nil {
-		// git log may not have found the commit where the file was added.
-		// Instead, retrieve all commits modifying the file and use the time
-		// from the first commit.
-		line, err = execCommand("git", "log", "--follow", "--find-renames=70%",
-			"--reverse", "--pretty=format:%cd", "--date=iso", "--", filename).CombinedOutput()
+// gitIndex is an index of the Git history of all files in a repository. It is
+// built using a single 'git log' walk, instead of running git for each file.
+type gitIndex struct {
+	root string
+
+	// files is the history of each file, keyed by the slash-separated path
+	// of the file relative to the repository root.
+	files map[string]*fileHistory
+
+	// dirty are the files that have been modified locally.
+	dirty map[string]bool
+}
+
+// fileHistory is the Git history of a file.
+type fileHistory struct {
+	// created is the time of the commit that added the file.
+	created time.Time
+
+	// modTimes are the times of the commits that modified the file, oldest
+	// first.
+	modTimes []time.Time
+
+	// truncated is whether the history of the file is truncated by a shallow
+	// clone, so the file may have been created before the visible history.
+	truncated bool
+}
+
+// gitIndexEntry is a cached Git index for a repository.
+type gitIndexEntry struct {
+	once  sync.Once
+	index *gitIndex
+	err   error
+}
+
+// gitIndexes are the Git indexes, keyed by repository root and the ignored
+// commits. Indexes are shared by all analyzer runs in the process.
+var gitIndexes sync.Map
+
+// gitIndexKey is the key of a Git index in gitIndexes.
+type gitIndexKey struct {
+	root       string
+	dateFormat string
+	ignore     string
+}
+
+// gitRoots are the Git repository roots, keyed by directory.
+var gitRoots sync.Map
+
+// gitRootEntry is a cached Git repository root for a directory.
+type gitRootEntry struct {
+	once sync.Once
+	root string
+	err  error
+}
+
+// gitRootFor returns the root of the Git repository containing the directory.
+// This is the root of the innermost repository, so directories in submodules
+// and linked worktrees return the root of the submodule or worktree. Roots are
+// cached per directory.
+func gitRootFor(dir string) (string, error) {
+	v, _ := gitRoots.LoadOrStore(dir, &gitRootEntry{})
+	e := v.(*gitRootEntry)
+	e.once.Do(func() {
+		out, err := gitOutput("-C", dir, "rev-parse", "--show-toplevel")
 		if err != nil {
-			return time.Time{}, time.Time{}, fmt.Errorf("could not get creation time from git: %w", err)
+			e.err = fmt.Errorf("find git repository for %s: %w", dir, err)
+			return
 		}
+		root := filepath.FromSlash(strings.TrimSpace(string(out)))
+		if resolved, err := filepath.EvalSymlinks(root); err == nil {
+			root = resolved
+		}
+		e.root = filepath.Clean(root)
+	})
+	return e.root, e.err
+}
+
+// gitPath returns the root of the Git repository containing the file, and the
+// path of the file relative to the repository root.
+func gitPath(filename string) (root, rel string, err error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return "", "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
 	}
-	creationTime, err := time.Parse(gitISOTimeFormat, string(line))
+	if root, err = gitRootFor(filepath.Dir(abs)); err != nil {
+		return "", "", err
+	}
+	if rel, err = relPath(root, abs); err != nil {
+		return "", "", err
+	}
+	return root, rel, nil
+}
+
+// gitIndexFor returns the Git index for the repository containing the file,
+// and the path of the file relative to the repository root.
+func (g *gitHistory) gitIndexFor(filename string) (*gitIndex, string, error) {
+	root, rel, err := gitPath(filename)
 	if err != nil {
-		return time.Time{}, time.Time{}, fmt.Errorf("could not get creation time from git: %w", err)
+		return nil, "", err
 	}
 
-	// Get file modification time. If the file has been modified locally, this
-	// will use the modification time on disk, otherwise the time of the last
-	// git commit that modified the file will be used.
-	modTime, err := lastModTime(filename)
+	key := gitIndexKey{root: root, dateFormat: g.dateFormat, ignore: g.ignore.key}
+	v, _ := gitIndexes.LoadOrStore(key, &gitIndexEntry{})
+	e := v.(*gitIndexEntry)
+	e.once.Do(func() {
+		e.index, e.err = buildGitIndex(root, g.dateFormat, g.ignore)
+	})
+	if e.err != nil {
+		return nil, "", e.err
+	}
+	return e.index, rel, nil
+}
+
+// buildGitIndex builds the Git index for the repository, using the commit date
+// from the dateFormat git log placeholder. Ignored commits are not recorded as
+// modifications.
+func buildGitIndex(root, dateFormat string, ignore *gitIgnore) (*gitIndex, error) {
+	shallow, err := gitShallowCommits(root)
+	if err != nil {
+		return nil, err
+	}
+
+	unborn, err := gitUnborn(root)
 	if err != nil {
-		return time.Time{}, time.Time{}, fmt.Errorf("could not get modification time: %w", err)
+		return nil, err
+	}
+	if unborn {
+		// The repository has no commits yet, so no file has any history.
+		return &gitIndex{root: root, files: map[string]*fileHistory{}, dirty: map[string]bool{}}, nil
 	}
 
-	return creationTime, modTime, nil
+	// Commits are listed in topological order, so renames are always seen
+	// before the older commits of the renamed file, even across merges.
+	format := gitCommitMarker + "%H" + gitFieldSeparator + dateFormat + gitFieldSeparator + "%s"
+	cmd := execCommand("git", "-C", root, "-c", "core.quotePath=false", "log", "--topo-order",
+		"--name-status", "--find-renames=70%", "--format=format:"+format)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+	done := startProcess()
+	if err = cmd.Start(); err != nil {
+		done()
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+	files, parseErr := parseGitLog(stdout, ignore, shallow)
+	if parseErr != nil {
+		// Drain the output to allow git to exit.
+		_, _ = io.Copy(io.Discard, stdout)
+	}
+	err = cmd.Wait()
+	done()
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+	if parseErr != nil {
+		return nil, fmt.Errorf("parse git log: %w", parseErr)
+	}
+
+	// Find locally modified files.
+	out, err := gitOutput("-C", root, "diff", "--name-only", "-z", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("git diff: %w", err)
+	}
+	dirty := make(map[string]bool)
+	for _, name := range bytes.Split(out, []byte{0}) {
+		if len(name) > 0 {
+			dirty[string(name)] = true
+		}
+	}
+
+	return &gitIndex{root: root, files: files, dirty: dirty}, nil
+}
+
+// gitUnborn returns whether HEAD is unborn, which is the case in a repository
+// without any commits.
+func gitUnborn(root string) (bool, error) {
+	_, err := gitOutput("-C", root, "rev-parse", "-q", "--verify", "HEAD")
+	if err == nil {
+		return false, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	return false, fmt.Errorf("git rev-parse: %w", err)
+}
+
+// gitShallowCommits returns the shallow boundary commits of the repository, or
+// nil if the repository is not a shallow clone. The history before these
+// commits is missing, so files appear to be added in them.
+func gitShallowCommits(root string) (map[string]bool, error) {
+	b, err := readGitShallow(root)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	shallow := make(map[string]bool)
+	for _, hash := range strings.Fields(string(b)) {
+		shallow[hash] = true
+	}
+	return shallow, nil
 }
 
-// gitModTimes returns the times of all commits that modify a file.
-func gitModTimes(filename string) ([]time.Time, error) {
-	lines, err := execCommand("git", "log", "--follow", "--find-renames=70%", "--diff-filter=ACMR",
-		"--reverse", "--pretty=format:%cd", "--date=iso", "--", filename).CombinedOutput()
+// gitShallowPaths are the paths of the shallow files of Git repositories,
+// keyed by the repository root.
+var gitShallowPaths sync.Map // map[string]*gitShallowPathEntry
+
+// gitShallowPathEntry is the path of the shallow file of a Git repository.
+type gitShallowPathEntry struct {
+	once sync.Once
+	path string
+	err  error
+}
+
+// readGitShallow returns the content of the shallow file of the repository,
+// which lists the shallow boundary commits, or nil if the repository is not a
+// shallow clone. The path of the file is cached, however the file is read on
+// each call, as it changes when the repository is deepened or unshallowed.
+func readGitShallow(root string) ([]byte, error) {
+	v, _ := gitShallowPaths.LoadOrStore(root, &gitShallowPathEntry{})
+	e := v.(*gitShallowPathEntry)
+	e.once.Do(func() {
+		out, err := gitOutput("-C", root, "rev-parse", "--git-path", "shallow")
+		if err != nil {
+			e.err = fmt.Errorf("git rev-parse: %w", err)
+			return
+		}
+		e.path = filepath.FromSlash(strings.TrimSpace(string(out)))
+		if !filepath.IsAbs(e.path) {
+			e.path = filepath.Join(root, e.path)
+		}
+	})
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	//nolint:gosec // Reading file from Git repository.
+	b, err := os.ReadFile(e.path)
 	if err != nil {
-		return nil, fmt.Errorf("could not get git history: %w", err)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read shallow commits: %w", err)
+	}
+	return b, nil
+}
+
+// parseGitLog parses the output of 'git log --topo-order --name-status',
+// newest commit first, into the history of each file. Renames are followed, so the history
+// of a file includes the history from before it was renamed.
+//
+// Commits matched by ignore are not recorded as modifications, however they are
+// still used to follow renames and to find when files were created. Files added
+// in a shallow boundary commit are marked as truncated.
+func parseGitLog(r io.Reader, ignore *gitIgnore, shallow map[string]bool) (map[string]*fileHistory, error) {
+	files := make(map[string]*fileHistory)
+
+	// alias maps a path in older commits to the current path of the file. An
+	// empty alias means the path did not hold a current file in older commits.
+	alias := make(map[string]string)
+	resolve := func(path string) string {
+		if key, ok := alias[path]; ok {
+			return key
+		}
+		return path
+	}
+	record := func(key string, t time.Time) *fileHistory {
+		fh, ok := files[key]
+		if !ok {
+			fh = &fileHistory{}
+			files[key] = fh
+		}
+		// Multiple changes in the same commit are recorded once.
+		if n := len(fh.modTimes); n == 0 || !fh.modTimes[n-1].Equal(t) {
+			fh.modTimes = append(fh.modTimes, t)
+		}
+		return fh
+	}
+
+	var (
+		commitTime time.Time
+		ignored    bool
+		boundary   bool
+	)
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		line := s.Text()
+		if line == "" {
+			continue
+		}
+		if commit, ok := strings.CutPrefix(line, gitCommitMarker); ok {
+			fields := strings.SplitN(commit, gitFieldSeparator, 3)
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("invalid commit line %q", commit)
+			}
+			hash, t, subject := fields[0], fields[1], fields[2]
+			var err error
+			if commitTime, err = time.Parse(time.RFC3339, t); err != nil {
+				return nil, fmt.Errorf("parse commit time %q: %w", t, err)
+			}
+			ignored = ignore.matches(hash, subject)
+			boundary = shallow[hash]
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 || fields[0] == "" {
+			return nil, fmt.Errorf("invalid status line %q", line)
+		}
+		paths := fields[1:]
+		for i, p := range paths {
+			if strings.HasPrefix(p, `"`) {
+				unquoted, err := strconv.Unquote(p)
+				if err != nil {
+					return nil, fmt.Errorf("unquote path %s: %w", p, err)
+				}
+				paths[i] = unquoted
+			}
+		}
+
+		switch fields[0][0] {
+		case 'M', 'T':
+			if key := resolve(paths[0]); key != "" && !ignored {
+				record(key, commitTime)
+			}
+		case 'A':
+			if key := resolve(paths[0]); key != "" {
+				fh := record(key, commitTime)
+				fh.created, fh.truncated = commitTime, boundary
+			}
+			// Older changes to the path are for a different file.
+			alias[paths[0]] = ""
+		case 'R':
+			if len(paths) != 2 {
+				return nil, fmt.Errorf("invalid rename status line %q", line)
+			}
+			oldPath, newPath := paths[0], paths[1]
+			key := resolve(newPath)
+			if key != "" && !ignored {
+				record(key, commitTime)
+			}
+			alias[newPath] = ""
+			alias[oldPath] = key
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
 	}
 
-	var modTimes []time.Time
-	for _, line := range strings.Split(string(lines), "\n") {
-		t, err := time.Parse(gitISOTimeFormat, line)
+	for _, fh := range files {
+		slices.Reverse(fh.modTimes)
+		if fh.created.IsZero() {
+			// The commit adding the file was not found, e.g. it was added in
+			// a merge commit.
+			fh.created = fh.modTimes[0]
+		}
+	}
+	return files, nil
+}
+
+// Git commit dates.
+const (
+	// GitDateCommitter uses the committer date of commits, which is when the
+	// commit was last applied, e.g. by a rebase or cherry-pick.
+	GitDateCommitter = "committer"
+
+	// GitDateAuthor uses the author date of commits, which is when the change
+	// was originally made.
+	GitDateAuthor = "author"
+)
+
+// GitHistoryOpts are the options for a Git History.
+type GitHistoryOpts struct {
+	// Date is the commit date used: "committer" (default) or "author".
+	Date string
+
+	// IgnoreRevsFile is a file containing commits to ignore, in the same
+	// format as a .git-blame-ignore-revs file: one commit hash per line, with
+	// '#' comments.
+	IgnoreRevsFile string
+
+	// IgnoreRevs are the hashes of commits to ignore. Abbreviated hashes are
+	// supported.
+	IgnoreRevs []string
+
+	// IgnoreMessages are regexps matched against commit subjects (the first
+	// line of the commit message). Matching commits are ignored.
+	IgnoreMessages []string
+}
+
+// gitHistory is a History using the Git history index.
+type gitHistory struct {
+	// dateFormat is the git log placeholder of the commit date.
+	dateFormat string
+	ignore     *gitIgnore
+}
+
+// defaultGitHistory is the Git History using committer dates, without ignored
+// commits.
+var defaultGitHistory = &gitHistory{dateFormat: "%cI", ignore: &gitIgnore{}}
+
+// GitHistory returns a History that reads the history of files from Git. The
+// Git history of each repository is indexed once and shared by all users in
+// the process. Files that are not tracked by Git or have been modified locally
+// use the modification time from the filesystem as their last modification
+// time.
+func GitHistory() History {
+	return defaultGitHistory
+}
+
+// NewGitHistory returns a Git History ignoring the configured commits. Ignored
+// commits, such as formatting sweeps or copyright year updates, are not counted
+// as modifications of the files they change. They are still used to find when
+// files were created and renamed.
+func NewGitHistory(opts GitHistoryOpts) (History, error) {
+	var dateFormat string
+	switch opts.Date {
+	case "", GitDateCommitter:
+		dateFormat = "%cI"
+	case GitDateAuthor:
+		dateFormat = "%aI"
+	default:
+		return nil, fmt.Errorf("invalid git date: %q", opts.Date)
+	}
+	ignore, err := newGitIgnore(opts)
+	if err != nil {
+		return nil, err
+	}
+	if dateFormat == defaultGitHistory.dateFormat && ignore.key == "" {
+		return defaultGitHistory, nil
+	}
+	return &gitHistory{dateFormat: dateFormat, ignore: ignore}, nil
+}
+
+// gitIgnore matches commits that are ignored.
+type gitIgnore struct {
+	revs     map[string]bool
+	abbrevs  []string
+	messages []*regexp.Regexp
+
+	// key uniquely identifies the ignored commits.
+	key string
+}
+
+// newGitIgnore creates a gitIgnore from the options.
+func newGitIgnore(opts GitHistoryOpts) (*gitIgnore, error) {
+	revs := slices.Clone(opts.IgnoreRevs)
+	if opts.IgnoreRevsFile != "" {
+		fileRevs, err := readIgnoreRevsFile(opts.IgnoreRevsFile)
 		if err != nil {
-			return nil, fmt.Errorf("could not parse git time %q: %w", line, err)
+			return nil, err
 		}
-		modTimes = append(modTimes, t)
+		revs = append(revs, fileRevs...)
 	}
 
-	// Check if file has changed locally.
-	diff, err := exec.Command("git", "diff", filename).CombinedOutput()
-	if err != nil && len(diff) > 0 {
-		// File has changed locally, add local modification time.
-		fsTime, err := fsModTime(filename)
+	ig := &gitIgnore{revs: make(map[string]bool)}
+	var key strings.Builder
+	slices.Sort(revs)
+	for _, rev := range slices.Compact(revs) {
+		rev = strings.ToLower(rev)
+		if len(rev) < 4 || strings.Trim(rev, "0123456789abcdef") != "" {
+			return nil, fmt.Errorf("invalid ignored commit: %q", rev)
+		}
+		if len(rev) == 40 || len(rev) == 64 {
+			ig.revs[rev] = true
+		} else {
+			ig.abbrevs = append(ig.abbrevs, rev)
+		}
+		key.WriteString(rev + "\n")
+	}
+	for _, m := range opts.IgnoreMessages {
+		re, err := regexp.Compile(m)
 		if err != nil {
-			return nil, fmt.Errorf("could not get fs modification time: %w", err)
+			return nil, fmt.Errorf("compile ignored commit message regexp: %w", err)
+		}
+		ig.messages = append(ig.messages, re)
+		key.WriteString(gitFieldSeparator + m + "\n")
+	}
+	ig.key = key.String()
+	return ig, nil
+}
+
+// readIgnoreRevsFile reads the commit hashes from an ignore revs file.
+func readIgnoreRevsFile(filename string) ([]string, error) {
+	//nolint:gosec // Reading user-defined file.
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("read ignore revs file: %w", err)
+	}
+	var revs []string
+	for _, line := range strings.Split(string(b), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.TrimSpace(line); line != "" {
+			revs = append(revs, line)
+		}
+	}
+	return revs, nil
+}
+
+// matches returns whether the commit is ignored.
+func (ig *gitIgnore) matches(hash, subject string) bool {
+	if ig == nil {
+		return false
+	}
+	if ig.revs[hash] {
+		return true
+	}
+	for _, abbrev := range ig.abbrevs {
+		if strings.HasPrefix(hash, abbrev) {
+			return true
 		}
-		modTimes = append(modTimes, fsTime)
 	}
+	for _, re := range ig.messages {
+		if re.MatchString(subject) {
+			return true
+		}
+	}
+	return false
+}
 
-	return modTimes, nil
+// file returns the history of the file and whether it has been modified
+// locally.
+func (g *gitHistory) file(filename string) (*fileHistory, bool, error) {
+	idx, path, err := g.gitIndexFor(filename)
+	if err != nil {
+		return nil, false, err
+	}
+	fh, ok := idx.files[path]
+	if !ok {
+		return nil, false, fmt.Errorf("%s: %w", filename, ErrNotInHistory)
+	}
+	return fh, idx.dirty[path], nil
+}
+
+func (g *gitHistory) Created(filename string) (time.Time, error) {
+	fh, _, err := g.file(filename)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not get creation time from git: %w", err)
+	}
+	if fh.truncated {
+		return fh.created, fmt.Errorf("%s: %w", filename, ErrHistoryTruncated)
+	}
+	return fh.created, nil
 }
 
-// lastModTime gets the last modification time for a file. It will run
-// 'git diff' to determine whether the file has been modified locally, and if
-// so, the local file modification time will be returned. Otherwise, the time
-// of the last Git commit that modified the file will be returned.
-func lastModTime(filename string) (time.Time, error) {
-	diff, err := execCommand("git", "diff", filename).CombinedOutput()
-	if err == nil && len(diff) == 0 {
-		// File has not changed locally, use git commit time.
-		return gitModTime(filename)
+// Modified returns the time of the last Git commit that modified the file. If
+// the file has been modified locally or is not in the Git history, the local
+// file modification time is returned.
+func (g *gitHistory) Modified(filename string) (time.Time, error) {
+	fh, dirty, err := g.file(filename)
+	if err != nil || dirty {
+		return fsModTime(filename)
 	}
-	return fsModTime(filename)
+	return fh.modTimes[len(fh.modTimes)-1], nil
 }
 
-// gitModTime returns the time of the last commit that modified a file.
-func gitModTime(filename string) (time.Time, error) {
-	line, err := execCommand("git", "log", "-1", "--pretty=format:%cd",
-		"--date=iso", "--", filename).CombinedOutput()
+func (g *gitHistory) ModTimes(filename string) ([]time.Time, error) {
+	fh, _, err := g.file(filename)
 	if err != nil {
-		return time.Time{}, err
+		return nil, fmt.Errorf("could not get git history: %w", err)
+	}
+	if fh.truncated {
+		return slices.Clone(fh.modTimes), fmt.Errorf("%s: %w", filename, ErrHistoryTruncated)
 	}
-	return time.Parse(gitISOTimeFormat, string(line))
+	return slices.Clone(fh.modTimes), nil
 }
 
-// fsModTime returns the file modification time from disk.
-func fsModTime(filename string) (time.Time, error) {
-	info, err := os.Stat(filename)
+func (g *gitHistory) IsDirty(filename string) (bool, error) {
+	idx, path, err := g.gitIndexFor(filename)
 	if err != nil {
-		return time.Time{}, err
+		return false, err
 	}
-	return info.ModTime(), nil
+	return idx.dirty[path], nil
 }
@@ -0,0 +1,513 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golicenser
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// gitLogCommit returns the commit line of a commit in the git log output.
+func gitLogCommit(hash, date, subject string) string {
+	return gitCommitMarker + hash + gitFieldSeparator + date + gitFieldSeparator + subject
+}
+
+func TestParseGitLog(t *testing.T) {
+	t.Parallel()
+
+	log := strings.Join([]string{
+		gitLogCommit("d4", "2025-03-01T10:00:00+00:00", "Add added.go"),
+		"",
+		"M\tpkg/new.go",
+		"A\tadded.go",
+		gitLogCommit("c3", "2024-06-01T10:00:00+00:00", "Rename old.go"),
+		"",
+		"R100\tpkg/old.go\tpkg/new.go",
+		"M\t\"quoted\\tname.go\"",
+		gitLogCommit("b2", "2023-01-01T10:00:00+00:00", "Remove added.go"),
+		"",
+		"M\tpkg/old.go",
+		"D\tadded.go",
+		gitLogCommit("a1", "2022-01-01T10:00:00+00:00", "Initial commit"),
+		"",
+		"A\tpkg/old.go",
+		"A\tadded.go",
+		"A\t\"quoted\\tname.go\"",
+		"",
+	}, "\n")
+
+	files, err := parseGitLog(strings.NewReader(log), nil, nil)
+	if err != nil {
+		t.Fatalf("parseGitLog() err = %v", err)
+	}
+
+	date := func(year int, month time.Month) time.Time {
+		return time.Date(year, month, 1, 10, 0, 0, 0, time.UTC)
+	}
+	tests := map[string]struct {
+		created  time.Time
+		modTimes []time.Time
+	}{
+		"pkg/new.go": {
+			created:  date(2022, time.January),
+			modTimes: []time.Time{date(2022, time.January), date(2023, time.January), date(2024, time.June), date(2025, time.March)},
+		},
+		"added.go": {
+			created:  date(2025, time.March),
+			modTimes: []time.Time{date(2025, time.March)},
+		},
+		"quoted\tname.go": {
+			created:  date(2022, time.January),
+			modTimes: []time.Time{date(2022, time.January), date(2024, time.June)},
+		},
+	}
+	for path, want := range tests {
+		fh, ok := files[path]
+		if !ok {
+			t.Errorf("files[%q] missing", path)
+			continue
+		}
+		if !fh.created.Equal(want.created) {
+			t.Errorf("files[%q].created = %v, want %v", path, fh.created, want.created)
+		}
+		if !slices.EqualFunc(fh.modTimes, want.modTimes, time.Time.Equal) {
+			t.Errorf("files[%q].modTimes = %v, want %v", path, fh.modTimes, want.modTimes)
+		}
+	}
+	if fh, ok := files["pkg/old.go"]; ok {
+		t.Errorf("files[pkg/old.go] = %+v, want renamed", fh)
+	}
+}
+
+func TestParseGitLogIgnore(t *testing.T) {
+	t.Parallel()
+
+	const (
+		hashA = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+		hashB = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+		hashC = "cccccccccccccccccccccccccccccccccccccccc"
+		hashD = "dddddddddddddddddddddddddddddddddddddddd"
+		hashE = "eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee"
+	)
+	log := strings.Join([]string{
+		gitLogCommit(hashE, "2025-01-01T00:00:00Z", "chore: update license headers"),
+		"",
+		"M	new.go",
+		gitLogCommit(hashD, "2024-01-01T00:00:00Z", "Run gofmt"),
+		"",
+		"R090	old.go	new.go",
+		gitLogCommit(hashC, "2023-01-01T00:00:00Z", "Fix bug"),
+		"",
+		"M	old.go",
+		gitLogCommit(hashB, "2022-01-01T00:00:00Z", "Bump copyright year"),
+		"",
+		"M	old.go",
+		gitLogCommit(hashA, "2020-01-01T00:00:00Z", "Initial commit"),
+		"",
+		"A	old.go",
+		"",
+	}, "\n")
+
+	ignore, err := newGitIgnore(GitHistoryOpts{
+		IgnoreRevs:     []string{hashB, hashD[:7]},
+		IgnoreMessages: []string{"^chore: update license"},
+	})
+	if err != nil {
+		t.Fatalf("newGitIgnore() err = %v", err)
+	}
+	files, err := parseGitLog(strings.NewReader(log), ignore, nil)
+	if err != nil {
+		t.Fatalf("parseGitLog() err = %v", err)
+	}
+
+	fh, ok := files["new.go"]
+	if !ok {
+		t.Fatalf("files[new.go] missing: %v", files)
+	}
+	var years []int
+	for _, mt := range fh.modTimes {
+		years = append(years, mt.Year())
+	}
+	if want := []int{2020, 2023}; !slices.Equal(years, want) {
+		t.Errorf("modTimes years = %v, want %v", years, want)
+	}
+	if got := fh.created.Year(); got != 2020 {
+		t.Errorf("created year = %d, want 2020", got)
+	}
+}
+
+func TestParseGitLogShallow(t *testing.T) {
+	t.Parallel()
+
+	log := strings.Join([]string{
+		gitLogCommit("b2", "2025-01-01T00:00:00Z", "Add new.go"),
+		"",
+		"A\tnew.go",
+		"M\told.go",
+		gitLogCommit("a1", "2024-01-01T00:00:00Z", "Shallow boundary"),
+		"",
+		"A\told.go",
+		"",
+	}, "\n")
+	files, err := parseGitLog(strings.NewReader(log), nil, map[string]bool{"a1": true})
+	if err != nil {
+		t.Fatalf("parseGitLog() err = %v", err)
+	}
+	if !files["old.go"].truncated {
+		t.Errorf("files[old.go].truncated = false, want true")
+	}
+	if files["new.go"].truncated {
+		t.Errorf("files[new.go].truncated = true, want false")
+	}
+}
+
+func TestNewGitIgnore(t *testing.T) {
+	t.Parallel()
+
+	revsFile := filepath.Join(t.TempDir(), ".git-blame-ignore-revs")
+	const revs = `# Run gofmt
+1234567890abcdef1234567890abcdef12345678
+
+ABCDEF0 # Update copyright years
+`
+	if err := os.WriteFile(revsFile, []byte(revs), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    GitHistoryOpts
+		ignored []string
+		kept    []string
+		wantErr bool
+	}{
+		{
+			name: "none",
+			kept: []string{"1234567890abcdef1234567890abcdef12345678"},
+		},
+		{
+			name:    "revs file",
+			opts:    GitHistoryOpts{IgnoreRevsFile: revsFile},
+			ignored: []string{"1234567890abcdef1234567890abcdef12345678", "abcdef0123456789abcdef0123456789abcdef01"},
+			kept:    []string{"1234567890abcdef1234567890abcdef12345679"},
+		},
+		{
+			name:    "missing revs file",
+			opts:    GitHistoryOpts{IgnoreRevsFile: filepath.Join(t.TempDir(), "missing")},
+			wantErr: true,
+		},
+		{
+			name:    "invalid rev",
+			opts:    GitHistoryOpts{IgnoreRevs: []string{"HEAD"}},
+			wantErr: true,
+		},
+		{
+			name:    "short rev",
+			opts:    GitHistoryOpts{IgnoreRevs: []string{"abc"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid message regexp",
+			opts:    GitHistoryOpts{IgnoreMessages: []string{"("}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ig, err := newGitIgnore(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newGitIgnore() err = %v, want err %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for _, hash := range tt.ignored {
+				if !ig.matches(hash, "") {
+					t.Errorf("matches(%s) = false, want true", hash)
+				}
+			}
+			for _, hash := range tt.kept {
+				if ig.matches(hash, "") {
+					t.Errorf("matches(%s) = true, want false", hash)
+				}
+			}
+		})
+	}
+}
+
+// runGit runs git in dir, with the author and committer date set to date.
+func runGit(t *testing.T, dir, date string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{
+		"-c", "user.name=Test", "-c", "user.email=test@example.com",
+		"-c", "commit.gpgSign=false", "-c", "protocol.file.allow=always",
+	}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// writeFile writes a file, creating its parent directories.
+func writeFile(t *testing.T, filename, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitIndex(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	dir := t.TempDir()
+	git := func(date string, args ...string) {
+		t.Helper()
+		runGit(t, dir, date, args...)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		writeFile(t, filepath.Join(dir, name), content)
+	}
+
+	git("", "init", "-q")
+	write("a.go", "package a\n")
+	git("2021-05-01T00:00:00Z", "add", ".")
+	git("2021-05-01T00:00:00Z", "commit", "-q", "-m", "add a.go")
+	write("a.go", "package a\n\nvar A = 1\n")
+	git("2023-05-01T00:00:00Z", "commit", "-q", "-am", "modify a.go")
+	git("2024-05-01T00:00:00Z", "mv", "a.go", "b.go")
+	git("2024-05-01T00:00:00Z", "commit", "-q", "-m", "rename a.go")
+	write("b.go", "package a\n\nvar A = 2\n")
+
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := buildGitIndex(root, "%cI", nil)
+	if err != nil {
+		t.Fatalf("buildGitIndex() err = %v", err)
+	}
+
+	fh, ok := idx.files["b.go"]
+	if !ok {
+		t.Fatalf("files[b.go] missing: %v", idx.files)
+	}
+	if got := fh.created.Year(); got != 2021 {
+		t.Errorf("created year = %d, want 2021", got)
+	}
+	var years []int
+	for _, mt := range fh.modTimes {
+		years = append(years, mt.Year())
+	}
+	if want := []int{2021, 2023, 2024}; !slices.Equal(years, want) {
+		t.Errorf("modTimes years = %v, want %v", years, want)
+	}
+	if !idx.dirty["b.go"] {
+		t.Errorf("dirty[b.go] = false, want true")
+	}
+
+	ignore, err := newGitIgnore(GitHistoryOpts{IgnoreMessages: []string{"^modify "}})
+	if err != nil {
+		t.Fatalf("newGitIgnore() err = %v", err)
+	}
+	if idx, err = buildGitIndex(root, "%cI", ignore); err != nil {
+		t.Fatalf("buildGitIndex() with ignore err = %v", err)
+	}
+	years = nil
+	for _, mt := range idx.files["b.go"].modTimes {
+		years = append(years, mt.Year())
+	}
+	if want := []int{2021, 2024}; !slices.Equal(years, want) {
+		t.Errorf("modTimes years with ignore = %v, want %v", years, want)
+	}
+}
+
+func TestGitIndexUnborn(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "", "init", "-q")
+	writeFile(t, filepath.Join(dir, "a.go"), "package a\n")
+	runGit(t, dir, "", "add", ".")
+
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := buildGitIndex(root, "%cI", nil)
+	if err != nil {
+		t.Fatalf("buildGitIndex() err = %v", err)
+	}
+	if len(idx.files) != 0 || len(idx.dirty) != 0 {
+		t.Errorf("buildGitIndex() files = %v, dirty = %v, want empty", idx.files, idx.dirty)
+	}
+
+	h := &gitHistory{dateFormat: "%cI", ignore: &gitIgnore{}}
+	filename := filepath.Join(root, "a.go")
+	if _, err = h.Created(filename); !errors.Is(err, ErrNotInHistory) {
+		t.Errorf("Created() err = %v, want ErrNotInHistory", err)
+	}
+	if _, err = h.Modified(filename); err != nil {
+		t.Errorf("Modified() err = %v", err)
+	}
+}
+
+func TestGitHistoryRepositories(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	tmp := t.TempDir()
+	outer := filepath.Join(tmp, "outer")
+	inner := filepath.Join(tmp, "inner")
+	worktree := filepath.Join(tmp, "worktree")
+
+	// Repository used as a submodule.
+	writeFile(t, filepath.Join(inner, "s.go"), "package s\n")
+	runGit(t, inner, "", "init", "-q")
+	runGit(t, inner, "2021-05-01T00:00:00Z", "add", ".")
+	runGit(t, inner, "2021-05-01T00:00:00Z", "commit", "-q", "-m", "add s.go")
+
+	// Outer repository, with the submodule and a linked worktree.
+	writeFile(t, filepath.Join(outer, "pkg", "o.go"), "package o\n")
+	runGit(t, outer, "", "init", "-q")
+	runGit(t, outer, "2020-05-01T00:00:00Z", "add", ".")
+	runGit(t, outer, "2020-05-01T00:00:00Z", "commit", "-q", "-m", "add o.go")
+	runGit(t, outer, "2022-05-01T00:00:00Z", "submodule", "add", "-q", inner, "sub")
+	runGit(t, outer, "2022-05-01T00:00:00Z", "commit", "-q", "-m", "add submodule")
+	runGit(t, outer, "", "worktree", "add", "-q", "-b", "feature", worktree)
+	writeFile(t, filepath.Join(worktree, "pkg", "o.go"), "package o\n\nvar O = 1\n")
+	runGit(t, worktree, "2023-05-01T00:00:00Z", "commit", "-q", "-am", "modify o.go")
+
+	tests := []struct {
+		filename     string
+		wantCreated  int
+		wantModified int
+	}{
+		{filename: filepath.Join(outer, "pkg", "o.go"), wantCreated: 2020, wantModified: 2020},
+		{filename: filepath.Join(outer, "sub", "s.go"), wantCreated: 2021, wantModified: 2021},
+		{filename: filepath.Join(worktree, "pkg", "o.go"), wantCreated: 2020, wantModified: 2023},
+	}
+	h := GitHistory()
+	for _, tt := range tests {
+		created, err := h.Created(tt.filename)
+		if err != nil {
+			t.Errorf("Created(%s) err = %v", tt.filename, err)
+		} else if created.Year() != tt.wantCreated {
+			t.Errorf("Created(%s) = %d, want %d", tt.filename, created.Year(), tt.wantCreated)
+		}
+		modified, err := h.Modified(tt.filename)
+		if err != nil {
+			t.Errorf("Modified(%s) err = %v", tt.filename, err)
+		} else if modified.Year() != tt.wantModified {
+			t.Errorf("Modified(%s) = %d, want %d", tt.filename, modified.Year(), tt.wantModified)
+		}
+	}
+}
+
+func TestGitHistoryShallow(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	tmp := t.TempDir()
+	origin := filepath.Join(tmp, "origin")
+	writeFile(t, filepath.Join(origin, "a.go"), "package a\n")
+	runGit(t, origin, "", "init", "-q")
+	runGit(t, origin, "2020-05-01T00:00:00Z", "add", ".")
+	runGit(t, origin, "2020-05-01T00:00:00Z", "commit", "-q", "-m", "add a.go")
+	writeFile(t, filepath.Join(origin, "a.go"), "package a\n\nvar A = 1\n")
+	runGit(t, origin, "2023-05-01T00:00:00Z", "commit", "-q", "-am", "modify a.go")
+	runGit(t, tmp, "", "clone", "-q", "--depth=1", "file://"+filepath.ToSlash(origin), "clone")
+
+	h := GitHistory()
+	filename := filepath.Join(tmp, "clone", "a.go")
+	created, err := h.Created(filename)
+	if !errors.Is(err, ErrHistoryTruncated) {
+		t.Errorf("Created() err = %v, want ErrHistoryTruncated", err)
+	}
+	if created.Year() != 2023 {
+		t.Errorf("Created() = %d, want visible year 2023", created.Year())
+	}
+	if _, err = h.Created(filepath.Join(origin, "a.go")); err != nil {
+		t.Errorf("Created() full history err = %v, want nil", err)
+	}
+}
+
+func TestGitHistoryDate(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.go"), "package a\n")
+	runGit(t, dir, "", "init", "-q")
+	runGit(t, dir, "2021-05-01T00:00:00Z", "add", ".")
+	runGit(t, dir, "2021-05-01T00:00:00Z", "commit", "-q",
+		"--date=2019-05-01T00:00:00Z", "-m", "add a.go")
+
+	tests := []struct {
+		date     string
+		wantYear int
+		wantErr  bool
+	}{
+		{date: "", wantYear: 2021},
+		{date: GitDateCommitter, wantYear: 2021},
+		{date: GitDateAuthor, wantYear: 2019},
+		{date: "birthday", wantErr: true},
+	}
+	for _, tt := range tests {
+		h, err := NewGitHistory(GitHistoryOpts{Date: tt.date})
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("NewGitHistory(%q) err = %v, want err %v", tt.date, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		created, err := h.Created(filepath.Join(dir, "a.go"))
+		if err != nil {
+			t.Fatalf("Created() err = %v", err)
+		}
+		if created.Year() != tt.wantYear {
+			t.Errorf("NewGitHistory(%q).Created() = %d, want %d", tt.date, created.Year(), tt.wantYear)
+		}
+	}
+}
@@ -6,8 +6,10 @@ toolchain go1.24.2
 
 require (
 	github.com/bmatcuk/doublestar/v4 v4.8.1
+	github.com/golangci/plugin-module-register v0.1.1
 	golang.org/x/sync v0.12.0
 	golang.org/x/tools v0.31.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require golang.org/x/mod v0.24.0 // indirect
@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package golangci provides a golangci-lint module plugin for golicenser.
+//
+// See https://golangci-lint.run/plugins/module-plugins/ for details on how to
+// build golangci-lint with module plugins.
+package golangci
+
+import (
+	"fmt"
+
+	"github.com/golangci/plugin-module-register/register"
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/joshuasing/golicenser"
+)
+
+// Name is the name the plugin is registered with.
+const Name = "golicenser"
+
+func init() {
+	registerPlugin(register.Plugin)
+}
+
+// registerPlugin registers the plugin using the given register function.
+func registerPlugin(fn func(name string, p register.NewPlugin)) {
+	fn(Name, New)
+}
+
+// Plugin is the golicenser golangci-lint plugin.
+type Plugin struct {
+	cfg golicenser.Config
+}
+
+var _ register.LinterPlugin = (*Plugin)(nil)
+
+// New creates a new golicenser plugin from the golangci-lint settings. The
+// settings are decoded into a golicenser.Config. Built-in templates can be used
+// by providing their SPDX identifier as the template or matcher.
+func New(settings any) (register.LinterPlugin, error) {
+	cfg, err := register.DecodeSettings[golicenser.Config](settings)
+	if err != nil {
+		return nil, fmt.Errorf("golicenser: %w", err)
+	}
+
+	resolveTemplates(&cfg.Header)
+	for i := range cfg.Rules {
+		resolveTemplates(&cfg.Rules[i].Header)
+	}
+	return &Plugin{cfg: cfg}, nil
+}
+
+// resolveTemplates replaces SPDX identifiers with the built-in templates.
+func resolveTemplates(h *golicenser.HeaderOpts) {
+	if tmpl, ok := golicenser.TemplateBySPDX(h.Template); ok {
+		h.Template = tmpl
+	}
+	if tmpl, ok := golicenser.TemplateBySPDX(h.Matcher); ok {
+		h.Matcher = tmpl
+	}
+	for i := range h.Legacy {
+		if tmpl, ok := golicenser.TemplateBySPDX(h.Legacy[i].Template); ok {
+			h.Legacy[i].Template = tmpl
+		}
+	}
+	for i := range h.Migrations {
+		m := &h.Migrations[i]
+		if tmpl, ok := golicenser.TemplateBySPDX(m.From); ok {
+			m.From = tmpl
+		}
+		if tmpl, ok := golicenser.TemplateBySPDX(m.To); ok {
+			m.To = tmpl
+		}
+	}
+}
+
+// BuildAnalyzers returns the golicenser analyzer.
+func (p *Plugin) BuildAnalyzers() ([]*analysis.Analyzer, error) {
+	a, err := golicenser.NewAnalyzer(p.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("golicenser: %w", err)
+	}
+	return []*analysis.Analyzer{a}, nil
+}
+
+// GetLoadMode returns the load mode for the plugin. golicenser only needs the
+// syntax of files.
+func (p *Plugin) GetLoadMode() string {
+	return register.LoadModeSyntax
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golangci
+
+import (
+	"testing"
+
+	"github.com/golangci/plugin-module-register/register"
+
+	"github.com/joshuasing/golicenser"
+)
+
+// fakeRegistry is a plugin registry used in place of the golangci-lint
+// registry.
+type fakeRegistry map[string]register.NewPlugin
+
+func (r fakeRegistry) register(name string, p register.NewPlugin) {
+	r[name] = p
+}
+
+func TestPlugin(t *testing.T) {
+	t.Parallel()
+
+	registry := make(fakeRegistry)
+	registerPlugin(registry.register)
+	newPlugin, ok := registry[Name]
+	if !ok {
+		t.Fatalf("plugin %q not registered", Name)
+	}
+
+	tests := []struct {
+		name     string
+		settings any
+		wantErr  bool // from New
+		check    func(t *testing.T, p *Plugin)
+	}{
+		{
+			name: "simple",
+			settings: map[string]any{
+				"header": map[string]any{
+					"template":  "MIT",
+					"author":    "Joshua Sing",
+					"year-mode": "git-range",
+					"variables": map[string]any{
+						"project": map[string]any{"value": "golicenser"},
+					},
+				},
+				"rules": []any{
+					map[string]any{
+						"paths": []any{"**/third_party/**"},
+						"header": map[string]any{
+							"template":      "Apache-2.0",
+							"author":        "Someone",
+							"comment-style": "block",
+						},
+					},
+				},
+				"exclude":        []any{"**/testdata/**"},
+				"max-concurrent": 4,
+			},
+			check: func(t *testing.T, p *Plugin) {
+				t.Helper()
+				if p.cfg.Header.Template != golicenser.LicenseMIT {
+					t.Errorf("Header.Template = %q, want MIT template", p.cfg.Header.Template)
+				}
+				if p.cfg.Header.YearMode != golicenser.YearModeGitRange {
+					t.Errorf("Header.YearMode = %v, want %v",
+						p.cfg.Header.YearMode, golicenser.YearModeGitRange)
+				}
+				if v := p.cfg.Header.Variables["project"]; v == nil || v.Value != "golicenser" {
+					t.Errorf("Header.Variables[project] = %v", v)
+				}
+				if len(p.cfg.Rules) != 1 {
+					t.Fatalf("Rules len = %d, want 1", len(p.cfg.Rules))
+				}
+				if h := p.cfg.Rules[0].Header; h.Template != golicenser.LicenseApache2 ||
+					h.CommentStyle != golicenser.CommentStyleBlock {
+					t.Errorf("Rules[0].Header = %+v", h)
+				}
+				if p.cfg.MaxConcurrent != 4 {
+					t.Errorf("MaxConcurrent = %d, want 4", p.cfg.MaxConcurrent)
+				}
+
+				analyzers, err := p.BuildAnalyzers()
+				if err != nil {
+					t.Fatalf("BuildAnalyzers() err = %v", err)
+				}
+				if len(analyzers) != 1 || analyzers[0].Name != "golicenser" {
+					t.Errorf("BuildAnalyzers() = %v, want golicenser analyzer", analyzers)
+				}
+				if mode := p.GetLoadMode(); mode != register.LoadModeSyntax {
+					t.Errorf("GetLoadMode() = %q, want %q", mode, register.LoadModeSyntax)
+				}
+			},
+		},
+		{
+			name: "unknown setting",
+			settings: map[string]any{
+				"header":  map[string]any{"template": "MIT", "author": "test"},
+				"unknown": true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid year mode",
+			settings: map[string]any{
+				"header": map[string]any{"template": "MIT", "author": "test", "year-mode": "invalid"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid header",
+			settings: map[string]any{
+				"header": map[string]any{"template": "MIT"},
+			},
+			check: func(t *testing.T, p *Plugin) {
+				t.Helper()
+				if _, err := p.BuildAnalyzers(); err == nil {
+					t.Errorf("BuildAnalyzers() err = nil, want error")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			lp, err := newPlugin(tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() err = %v, want err %v", err, tt.wantErr)
+			}
+			if tt.check != nil && lp != nil {
+				tt.check(t, lp.(*Plugin))
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"maps"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -35,12 +36,27 @@ import (
 // time output during tests for more reliable test runs.
 var timeNow = time.Now
 
+// yearRangeExpr matches a single year or a year range, e.g. "2025",
+// "2022-2025", "2015 - 2019", "2015–2019" and "2016-present".
+const yearRangeExpr = `\d{4}(?:[ \t]*[-–—][ \t]*(?:\d{4}|(?i:present)))?`
+
+// yearListExpr matches a comma-separated list of years and year ranges, e.g.
+// "2022, 2023, 2025", "2012-2014, 2018" and "2016,2018".
+const yearListExpr = yearRangeExpr + `(?:[ \t]*,[ \t]*` + yearRangeExpr + `)*`
+
 // regexpYears matches copyright years present in license headers. It will
-// match a single year, year range or listed years (comma-separated), e.g.
-// "2025", "2022-2025" and "2022, 2023, 2025".
-var regexpYears = regexp.MustCompile(`(?P<year>(\d{4})|(\d{4})-(\d{4])|(\d{4})(?:, (\d{4}))+)`)
+// match a single year, year range or a list of years and year ranges.
+var regexpYears = regexp.MustCompile(`(?P<year>` + yearListExpr + `)`)
+
+// regexpYearRange matches a single year or year range, capturing the start
+// and end year.
+var regexpYearRange = regexp.MustCompile(`(\d{4})(?:[ \t]*[-–—][ \t]*(\d{4}|(?i:present)))?`)
 
 // YearMode is a way of representing a copyright year(s) for a file.
+//
+// In addition to the named year modes below, a year mode can be parsed from a
+// year policy expression using ParseYearMode, which combines the sources of
+// the start and end years with a year format.
 type YearMode int
 
 const (
@@ -92,34 +108,58 @@ var yearModeStrings = map[YearMode]string{
 	YearModeGitModifiedList:       "git-modified-list",
 }
 
-// ParseYearMode parses a string representation of a year mode.
+// ParseYearMode parses a string representation of a year mode. This is
+// either the name of a year mode, such as "git-range", or a year policy
+// expression, such as "start=preserve|git-created end=git-modified
+// format=compact-list".
 func ParseYearMode(s string) (YearMode, error) {
-	switch strings.ToLower(s) {
-	case yearModeStrings[YearModePreserve]:
-		return YearModePreserve, nil
-	case yearModeStrings[YearModePreserveThisYearRange]:
-		return YearModePreserveThisYearRange, nil
-	case yearModeStrings[YearModePreserveModifiedRange]:
-		return YearModePreserveModifiedRange, nil
-	case yearModeStrings[YearModeThisYear]:
-		return YearModeThisYear, nil
-	case yearModeStrings[YearModeLastModified]:
-		return YearModeLastModified, nil
-	case yearModeStrings[YearModeGitRange]:
-		return YearModeGitRange, nil
-	case yearModeStrings[YearModeGitModifiedList]:
-		return YearModeGitModifiedList, nil
-	default:
-		return 0, fmt.Errorf("invalid year mode: %q", s)
+	for ym, str := range yearModeStrings {
+		if strings.EqualFold(s, str) {
+			return ym, nil
+		}
+	}
+	if strings.Contains(s, "=") {
+		p, err := parseYearPolicy(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid year mode: %q: %w", s, err)
+		}
+		return p.mode(), nil
 	}
+	return 0, fmt.Errorf("invalid year mode: %q", s)
 }
 
-// String returns a string representation of the year mode.
+// String returns a string representation of the year mode. Year modes parsed
+// from a year policy expression are returned as a canonical expression.
 func (ym YearMode) String() string {
-	return yearModeStrings[ym]
+	if s, ok := yearModeStrings[ym]; ok {
+		return s
+	}
+	if p, ok := ym.policy(); ok {
+		return p.String()
+	}
+	return ""
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (ym YearMode) MarshalText() ([]byte, error) {
+	s := ym.String()
+	if s == "" {
+		return nil, fmt.Errorf("invalid year mode: %d", ym)
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (ym *YearMode) UnmarshalText(text []byte) error {
+	v, err := ParseYearMode(string(text))
+	if err != nil {
+		return err
+	}
+	*ym = v
+	return nil
 }
 
-// CommentStyle is a type of Go source code comment.
+// CommentStyle is a type of source code comment.
 type CommentStyle int
 
 const (
@@ -130,30 +170,195 @@ const (
 	// I strongly discourage using this as it is more idiomatic to use
 	// CommentStyleLine.
 	CommentStyleBlock
+
+	// CommentStyleHash uses hash line comments (# test), used by shell
+	// scripts, Makefiles, Dockerfiles, YAML, etc. This cannot be used for Go
+	// files.
+	CommentStyleHash
+
+	// CommentStyleDoubleDash uses double-dash line comments (-- test), used by
+	// SQL, Lua, etc. This cannot be used for Go files.
+	CommentStyleDoubleDash
+
+	// CommentStyleXML uses XML block comments (<!-- test -->), used by XML and
+	// HTML. This cannot be used for Go files.
+	CommentStyleXML
 )
 
+var commentStyleStrings = map[CommentStyle]string{
+	CommentStyleLine:       "line",
+	CommentStyleBlock:      "block",
+	CommentStyleHash:       "hash",
+	CommentStyleDoubleDash: "double-dash",
+	CommentStyleXML:        "xml",
+}
+
+// linePrefixes are the comment prefixes for line comment styles.
+var linePrefixes = map[CommentStyle]string{
+	CommentStyleLine:       "//",
+	CommentStyleHash:       "#",
+	CommentStyleDoubleDash: "--",
+}
+
+// blockDelimiters are the start and end delimiters for block comment styles.
+var blockDelimiters = map[CommentStyle][2]string{
+	CommentStyleBlock: {"/*", "*/"},
+	CommentStyleXML:   {"<!--", "-->"},
+}
+
 // ParseCommentStyle parses a string representation of a comment style.
 func ParseCommentStyle(s string) (CommentStyle, error) {
-	switch strings.ToLower(s) {
-	case CommentStyleLine.String():
-		return CommentStyleLine, nil
-	case CommentStyleBlock.String():
-		return CommentStyleBlock, nil
-	default:
-		return 0, fmt.Errorf("invalid comment style: %q", s)
+	for cs, str := range commentStyleStrings {
+		if strings.EqualFold(s, str) {
+			return cs, nil
+		}
 	}
+	return 0, fmt.Errorf("invalid comment style: %q", s)
 }
 
 // String returns a string representation of the comment style.
 func (cs CommentStyle) String() string {
-	switch cs {
-	case CommentStyleLine:
-		return "line"
-	case CommentStyleBlock:
-		return "block"
-	default:
-		return ""
+	return commentStyleStrings[cs]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (cs CommentStyle) MarshalText() ([]byte, error) {
+	s := cs.String()
+	if s == "" {
+		return nil, fmt.Errorf("invalid comment style: %d", cs)
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (cs *CommentStyle) UnmarshalText(text []byte) error {
+	v, err := ParseCommentStyle(string(text))
+	if err != nil {
+		return err
 	}
+	*cs = v
+	return nil
+}
+
+// isGo returns whether the comment style can be used in Go source files.
+func (cs CommentStyle) isGo() bool {
+	return cs == CommentStyleLine || cs == CommentStyleBlock
+}
+
+// LegacySeverity is how a legacy license header is handled.
+type LegacySeverity int
+
+const (
+	// LegacyAccept accepts legacy license headers without reporting them.
+	LegacyAccept LegacySeverity = iota
+
+	// LegacyWarn reports legacy license headers, without a suggested fix.
+	LegacyWarn
+
+	// LegacyMigrate reports legacy license headers as invalid, with a
+	// suggested fix which updates them to the license header.
+	LegacyMigrate
+)
+
+var legacySeverityStrings = map[LegacySeverity]string{
+	LegacyAccept:  "accept",
+	LegacyWarn:    "warn",
+	LegacyMigrate: "migrate",
+}
+
+// ParseLegacySeverity parses a string representation of a legacy severity.
+func ParseLegacySeverity(s string) (LegacySeverity, error) {
+	for ls, str := range legacySeverityStrings {
+		if strings.EqualFold(s, str) {
+			return ls, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid legacy severity: %q", s)
+}
+
+// String returns a string representation of the legacy severity.
+func (ls LegacySeverity) String() string {
+	return legacySeverityStrings[ls]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (ls LegacySeverity) MarshalText() ([]byte, error) {
+	s := ls.String()
+	if s == "" {
+		return nil, fmt.Errorf("invalid legacy severity: %d", ls)
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (ls *LegacySeverity) UnmarshalText(text []byte) error {
+	v, err := ParseLegacySeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*ls = v
+	return nil
+}
+
+// ShallowPolicy is how copyright years are calculated when the history of a
+// file is truncated, such as in a shallow Git clone.
+type ShallowPolicy int
+
+const (
+	// ShallowTrustExisting uses the start year of the existing license header
+	// if it is earlier than the visible history.
+	ShallowTrustExisting ShallowPolicy = iota
+
+	// ShallowPreserve uses the start year of the existing license header, if
+	// any, in place of the creation year from the history.
+	ShallowPreserve
+
+	// ShallowFail reports an error when the history is truncated.
+	ShallowFail
+
+	// ShallowIgnore uses the visible history as if it were complete.
+	ShallowIgnore
+)
+
+var shallowPolicyStrings = map[ShallowPolicy]string{
+	ShallowTrustExisting: "trust-existing",
+	ShallowPreserve:      "preserve",
+	ShallowFail:          "fail",
+	ShallowIgnore:        "ignore",
+}
+
+// ParseShallowPolicy parses a string representation of a shallow policy.
+func ParseShallowPolicy(s string) (ShallowPolicy, error) {
+	for sp, str := range shallowPolicyStrings {
+		if strings.EqualFold(s, str) {
+			return sp, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid shallow policy: %q", s)
+}
+
+// String returns a string representation of the shallow policy.
+func (sp ShallowPolicy) String() string {
+	return shallowPolicyStrings[sp]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (sp ShallowPolicy) MarshalText() ([]byte, error) {
+	s := sp.String()
+	if s == "" {
+		return nil, fmt.Errorf("invalid shallow policy: %d", sp)
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (sp *ShallowPolicy) UnmarshalText(text []byte) error {
+	v, err := ParseShallowPolicy(string(text))
+	if err != nil {
+		return err
+	}
+	*sp = v
+	return nil
 }
 
 // detectCommentStyle attempts to detect the comment style from a comment.
@@ -163,6 +368,12 @@ func detectCommentStyle(s string) (CommentStyle, error) {
 		return CommentStyleLine, nil
 	case strings.HasPrefix(s, "/*\n"):
 		return CommentStyleBlock, nil
+	case strings.HasPrefix(s, "#"):
+		return CommentStyleHash, nil
+	case strings.HasPrefix(s, "--"):
+		return CommentStyleDoubleDash, nil
+	case strings.HasPrefix(s, "<!--\n"):
+		return CommentStyleXML, nil
 	default:
 		return 0, fmt.Errorf("not a comment: %q", s)
 	}
@@ -170,11 +381,10 @@ func detectCommentStyle(s string) (CommentStyle, error) {
 
 // Render renders the string into a comment.
 func (cs CommentStyle) Render(s string) string {
-	switch cs {
-	case CommentStyleLine:
+	if prefix, ok := linePrefixes[cs]; ok {
 		var b bytes.Buffer
 		for _, l := range strings.Split(s, "\n") {
-			b.WriteString("//")
+			b.WriteString(prefix)
 			if l != "" {
 				b.WriteRune(' ')
 				b.WriteString(l)
@@ -182,36 +392,37 @@ func (cs CommentStyle) Render(s string) string {
 			b.WriteRune('\n')
 		}
 		return b.String()
-	case CommentStyleBlock:
-		return "/*\n" + s + "\n*/\n"
-	default:
-		// Cannot render as a comment.
-		return s
 	}
+	if delims, ok := blockDelimiters[cs]; ok {
+		return delims[0] + "\n" + s + "\n" + delims[1] + "\n"
+	}
+
+	// Cannot render as a comment.
+	return s
 }
 
 // Parse parses the comment and returns the uncommented string.
 func (cs CommentStyle) Parse(s string) string {
-	switch cs {
-	case CommentStyleLine:
+	if prefix, ok := linePrefixes[cs]; ok {
 		var b bytes.Buffer
 		for i, l := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
 			if i != 0 {
 				b.WriteRune('\n')
 			}
-			l = strings.TrimPrefix(l, "//")
+			l = strings.TrimPrefix(l, prefix)
 			if len(l) > 1 && l[0] == ' ' {
 				l = l[1:]
 			}
 			b.WriteString(l)
 		}
 		return b.String()
-	case CommentStyleBlock:
-		return strings.TrimSuffix(strings.TrimPrefix(s, "/*\n"), "\n*/\n")
-	default:
-		// Cannot parse as a comment.
-		return s
 	}
+	if delims, ok := blockDelimiters[cs]; ok {
+		return strings.TrimSuffix(strings.TrimPrefix(s, delims[0]+"\n"), "\n"+delims[1]+"\n")
+	}
+
+	// Cannot parse as a comment.
+	return s
 }
 
 // Header is a helper for generating and updating license headers.
@@ -219,6 +430,30 @@ type Header struct {
 	tmpl    *template.Template
 	matcher *regexp.Regexp
 
+	// equivalents match headers that are accepted as equivalent to the
+	// header, such as the full license text of an SPDX short-form header.
+	equivalents []*regexp.Regexp
+
+	// legacy match previous license headers.
+	legacy []legacyHeader
+
+	// migrations migrate license headers from previous licenses.
+	migrations []migration
+
+	// history provides the file history used by year modes.
+	history History
+
+	// shallowPolicy is how years are calculated when the history is
+	// truncated.
+	shallowPolicy ShallowPolicy
+
+	// location is the time zone used for year boundaries, or nil to use the
+	// location of each time.
+	location *time.Location
+
+	// clock returns the current time, or nil to use the wall clock.
+	clock func() time.Time
+
 	author       string
 	variables    map[string]*Var
 	yearMode     YearMode
@@ -232,23 +467,113 @@ var tmplFuncMap = template.FuncMap{
 // Var is a template variable.
 type Var struct {
 	// Value is the variable value.
-	Value string
+	Value string `json:"value"`
 
 	// Regexp is a regexp used to match the variable value.
 	// If empty, the regexp-escaped value of Value will be used.
-	Regexp string
+	Regexp string `json:"regexp,omitempty"`
+}
+
+// LegacyHeader is a previous license header, such as a header from before
+// relicensing or changing the author, which is accepted as valid.
+type LegacyHeader struct {
+	// Template is the previous header template. It is used to create the
+	// matcher if Matcher is empty.
+	Template string `json:"template,omitempty"`
+
+	// Matcher is the previous header matcher. See HeaderOpts.Matcher.
+	Matcher       string `json:"matcher,omitempty"`
+	MatcherEscape bool   `json:"matcher-escape,omitempty"`
+
+	// Author and AuthorRegexp are used to match the previous copyright
+	// author. If both are empty, the author of the header is used.
+	Author       string `json:"author,omitempty"`
+	AuthorRegexp string `json:"author-regexp,omitempty"`
+
+	// Severity is how matching headers are handled.
+	Severity LegacySeverity `json:"severity"`
+}
+
+// legacyHeader is a compiled legacy license header.
+type legacyHeader struct {
+	matcher  *regexp.Regexp
+	severity LegacySeverity
+}
+
+// Migration migrates license headers from a previous license to the license
+// header, carrying fields such as the year and author over from the previous
+// license header.
+type Migration struct {
+	// From is the template of the previous license header. It is used to
+	// create the matcher if FromMatcher is empty.
+	From string `json:"from,omitempty"`
+
+	// FromMatcher is the matcher for the previous license header. See
+	// HeaderOpts.Matcher. Fields are captured using named capture groups,
+	// e.g. (?P<author>.+).
+	FromMatcher       string `json:"from-matcher,omitempty"`
+	FromMatcherEscape bool   `json:"from-matcher-escape,omitempty"`
+
+	// To is the template of the new license header. If empty, the header
+	// template is used. Migrated headers should be matched by the header
+	// matcher.
+	To string `json:"to,omitempty"`
+
+	// Carry are the fields carried over from the previous license header:
+	// "year", "author" or a variable name. If nil, the year and author are
+	// carried over.
+	Carry []string `json:"carry,omitempty"`
+}
+
+// migration is a compiled header migration.
+type migration struct {
+	matcher *regexp.Regexp
+	tmpl    *template.Template
+	carry   []string
 }
 
 // HeaderOpts are the options for creating a license header.
+//
+// If SPDX is set to an SPDX license expression, the header is an SPDX
+// short-form header. The expression is available to templates as the "spdx"
+// variable, and SPDXTemplate is used if Template is empty. Headers containing
+// the full built-in license text of a single-license expression are accepted
+// as equivalent.
 type HeaderOpts struct {
-	Template      string
-	Matcher       string
-	MatcherEscape bool
-	Author        string
-	AuthorRegexp  string
-	Variables     map[string]*Var
-	YearMode      YearMode
-	CommentStyle  CommentStyle
+	Template      string          `json:"template"`
+	SPDX          string          `json:"spdx,omitempty"`
+	Matcher       string          `json:"matcher,omitempty"`
+	MatcherEscape bool            `json:"matcher-escape,omitempty"`
+	Author        string          `json:"author"`
+	AuthorRegexp  string          `json:"author-regexp,omitempty"`
+	Variables     map[string]*Var `json:"variables,omitempty"`
+	YearMode      YearMode        `json:"year-mode"`
+	CommentStyle  CommentStyle    `json:"comment-style"`
+
+	// Legacy are previous license headers that are accepted as valid, with
+	// the severity used to report them.
+	Legacy []LegacyHeader `json:"legacy,omitempty"`
+
+	// Migrations migrate license headers from previous licenses.
+	Migrations []Migration `json:"migrations,omitempty"`
+
+	// History provides the file history used by year modes. If nil, the
+	// Git history is used.
+	History History `json:"-"`
+
+	// ShallowPolicy is how years are calculated by Git year modes when the
+	// history is truncated, such as in a shallow clone.
+	ShallowPolicy ShallowPolicy `json:"shallow-policy,omitempty"`
+
+	// Timezone is the IANA time zone name (e.g. "UTC") used for year
+	// boundaries. If empty, the time zone of each time is used: the local
+	// time zone for the current time and file modification times, and the
+	// time zone recorded in the history for commit times.
+	Timezone string `json:"timezone,omitempty"`
+
+	// Clock returns the current time, used for the current year. If nil, the
+	// wall clock is used. A fixed clock makes headers reproducible.
+	Clock func() time.Time `json:"-"`
 }
 
 // NewHeader creates a new header with the given options.
@@ -256,6 +581,25 @@ func NewHeader(opts HeaderOpts) (*Header, error) {
 	if opts.Author == "" {
 		return nil, fmt.Errorf("invalid author: %q", opts.Author)
 	}
+
+	// SPDX short-form header.
+	var spdxLicenses []string
+	if opts.SPDX != "" {
+		expr, licenses, err := parseSPDX(opts.SPDX)
+		if err != nil {
+			return nil, err
+		}
+		spdxLicenses = licenses
+		if opts.Template == "" {
+			opts.Template = SPDXTemplate
+		}
+		opts.Variables = maps.Clone(opts.Variables)
+		if opts.Variables == nil {
+			opts.Variables = make(map[string]*Var, 1)
+		}
+		opts.Variables["spdx"] = &Var{Value: expr, Regexp: regexp.QuoteMeta(expr)}
+	}
+
 	if opts.Template == "" {
 		return nil, fmt.Errorf("invalid template: %q", opts.Template)
 	}
@@ -320,103 +664,328 @@ func NewHeader(opts HeaderOpts) (*Header, error) {
 		}
 	}
 
+	// A single-license SPDX expression accepts the full license text of the
+	// license as an equivalent header.
+	var equivalents []*regexp.Regexp
+	if len(spdxLicenses) == 1 && !strings.ContainsAny(opts.Variables["spdx"].Value, "+ ") {
+		if full, ok := TemplateBySPDX(spdxLicenses[0]); ok {
+			ft, err := template.New("").Funcs(tmplFuncMap).
+				Option("missingkey=error").Parse(full)
+			if err != nil {
+				return nil, fmt.Errorf("new %s template: %w", spdxLicenses[0], err)
+			}
+			em, err := headerMatcher(ft, true, authorRegexp, opts.Variables)
+			if err != nil {
+				return nil, fmt.Errorf("create %s header matcher: %w", spdxLicenses[0], err)
+			}
+			equivalents = append(equivalents, em)
+		}
+	}
+
+	// Create legacy header matchers.
+	legacy := make([]legacyHeader, 0, len(opts.Legacy))
+	for i, l := range opts.Legacy {
+		lm, err := legacyMatcher(l, authorRegexp, opts.Variables)
+		if err != nil {
+			return nil, fmt.Errorf("legacy header %d: %w", i, err)
+		}
+		legacy = append(legacy, legacyHeader{matcher: lm, severity: l.Severity})
+	}
+
+	// Create header migrations.
+	migrations := make([]migration, 0, len(opts.Migrations))
+	for i, mg := range opts.Migrations {
+		to := t
+		if mg.To != "" {
+			if to, err = template.New("").Funcs(tmplFuncMap).
+				Option("missingkey=error").Parse(mg.To); err != nil {
+				return nil, fmt.Errorf("migration %d: new template: %w", i, err)
+			}
+			if err = to.Execute(io.Discard, m); err != nil {
+				return nil, fmt.Errorf("migration %d: execute template: %w", i, err)
+			}
+		}
+		mm, err := migrationMatcher(mg, opts.Variables)
+		if err != nil {
+			return nil, fmt.Errorf("migration %d: %w", i, err)
+		}
+		carry := mg.Carry
+		if carry == nil {
+			carry = []string{"year", "author"}
+		}
+		for _, name := range carry {
+			if _, ok := m[name]; !ok {
+				return nil, fmt.Errorf("migration %d: unknown carry field %q", i, name)
+			}
+		}
+		migrations = append(migrations, migration{matcher: mm, tmpl: to, carry: carry})
+	}
+
+	history := opts.History
+	if history == nil {
+		history = GitHistory()
+	}
+
+	var location *time.Location
+	if opts.Timezone != "" {
+		if location, err = time.LoadLocation(opts.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone: %w", err)
+		}
+	}
+
 	return &Header{
-		tmpl:         t,
-		matcher:      matcher,
-		author:       opts.Author,
-		variables:    opts.Variables,
-		yearMode:     opts.YearMode,
-		commentStyle: opts.CommentStyle,
+		tmpl:          t,
+		matcher:       matcher,
+		equivalents:   equivalents,
+		legacy:        legacy,
+		migrations:    migrations,
+		history:       history,
+		shallowPolicy: opts.ShallowPolicy,
+		location:      location,
+		clock:         opts.Clock,
+		author:        opts.Author,
+		variables:     opts.Variables,
+		yearMode:      opts.YearMode,
+		commentStyle:  opts.CommentStyle,
 	}, nil
 }
 
-// Create creates a new license header for the file.
+// withYearMode returns a copy of the header using the year mode.
+func (h *Header) withYearMode(ym YearMode) *Header {
+	c := *h
+	c.yearMode = ym
+	return &c
+}
+
+// Create creates a new license header for the file. The copyright year(s)
+// are calculated using the year mode in the same way as Update, without an
+// existing year.
 func (h *Header) Create(filename string) (string, error) {
-	header, err := h.render(filename, timeNow().Format("2006"))
+	return h.create(filename, h.commentStyle)
+}
+
+// create creates a new license header for the file, using the given comment
+// style.
+func (h *Header) create(filename string, style CommentStyle) (string, error) {
+	year, err := h.year(filename, "")
+	if err != nil {
+		return "", err
+	}
+	header, err := h.render(filename, year)
 	if err != nil {
 		return "", fmt.Errorf("render header: %w", err)
 	}
-	return h.commentStyle.Render(header), nil
+	return style.Render(header), nil
 }
 
-// Update updates an existing license header if it matches the
+// headerMatch is the result of matching an existing license header.
+type headerMatch int
+
+const (
+	// headerUnrecognised is a header that does not match the header.
+	headerUnrecognised headerMatch = iota
+
+	// headerMatched is a header matched by the header matcher.
+	headerMatched
+
+	// headerEquivalent is a header accepted as equivalent to the header.
+	headerEquivalent
+
+	// headerLegacy is a header matched by a legacy header matcher.
+	headerLegacy
+
+	// headerMigration is a header matched by a migration.
+	headerMigration
+)
+
+// Matches returns whether the license header matches the header matcher, or
+// is accepted as equivalent to the header or as a legacy header. Equivalent
+// and legacy headers are left as-is by Update.
+func (h *Header) Matches(header string) bool {
+	m, _ := h.match(header)
+	return m != headerUnrecognised && m != headerMigration
+}
+
+// match matches an existing license header. If the header is a legacy header
+// or matched by a migration, the index of the legacy header or migration is
+// returned.
+//
+// Matchers are not anchored, so a header may be matched by several matchers,
+// e.g. a short header matches the start of a longer legacy header. The matcher
+// matching the most of the header is used, preferring the header matcher.
+func (h *Header) match(header string) (headerMatch, int) {
+	if cs, err := detectCommentStyle(header); err == nil {
+		header = cs.Parse(header)
+	}
+
+	best, index, bestLen := headerUnrecognised, -1, -1
+	try := func(m *regexp.Regexp, match headerMatch, i int) {
+		if loc := m.FindStringIndex(header); loc != nil && loc[1]-loc[0] > bestLen {
+			best, index, bestLen = match, i, loc[1]-loc[0]
+		}
+	}
+	try(h.matcher, headerMatched, -1)
+	for _, m := range h.equivalents {
+		try(m, headerEquivalent, -1)
+	}
+	for i, l := range h.legacy {
+		try(l.matcher, headerLegacy, i)
+	}
+	for i, mg := range h.migrations {
+		try(mg.matcher, headerMigration, i)
+	}
+	return best, index
+}
+
+// Migrate migrates a license header from a previous license, if it is matched
+// by a migration. The fields carried by the migration are taken from the
+// existing license header.
+func (h *Header) Migrate(filename, header string) (string, bool, error) {
+	match, i := h.match(header)
+	if match != headerMigration {
+		return header, false, nil
+	}
+	newHeader, err := h.migrate(&h.migrations[i], filename, header, h.commentStyle)
+	if err != nil {
+		return "", false, err
+	}
+	return newHeader, true, nil
+}
+
+// migrate migrates a license header matched by the migration, using the given
+// comment style.
+func (h *Header) migrate(mg *migration, filename, header string, style CommentStyle) (string, error) {
+	if cs, err := detectCommentStyle(header); err == nil {
+		header = cs.Parse(header)
+	}
+	match := mg.matcher.FindStringSubmatch(header)
+	if match == nil {
+		return "", fmt.Errorf("header not matched by migration")
+	}
+
+	carried := make(map[string]string, len(mg.carry))
+	for _, name := range mg.carry {
+		if i := mg.matcher.SubexpIndex(name); i != -1 && match[i] != "" {
+			carried[name] = match[i]
+		}
+	}
+	year, err := h.year(filename, carried["year"])
+	if err != nil {
+		return "", err
+	}
+	delete(carried, "year")
+
+	newHeader, err := h.renderWith(mg.tmpl, filename, year, carried)
+	if err != nil {
+		return "", fmt.Errorf("render header: %w", err)
+	}
+	return style.Render(newHeader), nil
+}
+
+// Update updates an existing license header if it matches the header matcher.
 func (h *Header) Update(filename, header string) (string, bool, error) {
+	return h.update(filename, header, h.commentStyle)
+}
+
+// update updates an existing license header, using the given comment style.
+func (h *Header) update(filename, header string, style CommentStyle) (string, bool, error) {
+	return h.updateMatched(h.matcher, filename, header, style)
+}
+
+// updateMatched updates an existing license header matched by matcher to the
+// license header. The existing year is taken from the "year" capture group of
+// the matcher.
+func (h *Header) updateMatched(matcher *regexp.Regexp, filename, header string, style CommentStyle) (string, bool, error) {
 	cs, err := detectCommentStyle(header)
 	if err == nil {
 		header = cs.Parse(header)
 	}
-	match := h.matcher.FindStringSubmatch(header)
+	match := matcher.FindStringSubmatch(header)
 	if match == nil {
 		return header, false, nil
 	}
 
-	var year string
-	switch h.yearMode {
-	case YearModePreserve:
-		if i := h.matcher.SubexpIndex("year"); i != -1 {
-			year = match[i]
-		}
-	case YearModePreserveThisYearRange:
-		if i := h.matcher.SubexpIndex("year"); i != -1 {
-			year = match[i]
-			if parts := strings.SplitN(year, "-", 2); len(parts) > 1 {
-				year = parts[0]
-			}
-			if currentYear := timeNow().Format("2006"); year != currentYear {
-				year += "-" + currentYear
-			}
-		}
-	case YearModePreserveModifiedRange:
-		if i := h.matcher.SubexpIndex("year"); i != -1 {
-			year = match[i]
-			if modTime, err := lastModTime(filename); err == nil {
-				if parts := strings.SplitN(year, "-", 2); len(parts) > 1 {
-					year = parts[0]
-				}
-				if modifiedYear := modTime.Format("2006"); year != modifiedYear {
-					year += "-" + modifiedYear
-				}
-			}
-		}
-	case YearModeThisYear:
-		// Handled below switch.
-	case YearModeLastModified:
-		if modTime, err := lastModTime(filename); err == nil {
-			year = modTime.Format("2006")
-		}
-	case YearModeGitRange:
-		if created, modified, err := gitModRange(filename); err == nil {
-			if created.Year() == modified.Year() {
-				year = created.Format("2006")
-				break
-			}
-			year = created.Format("2006") + "-" + modified.Format("2006")
-		}
-	case YearModeGitModifiedList:
-		if modTimes, err := gitModTimes(filename); err == nil && len(modTimes) > 0 {
-			year = modTimes[0].Format("2006")
-			for i, modTime := range modTimes[1:] {
-				if modTimes[i].Year() == modTime.Year() {
-					continue
-				}
-				year = year + ", " + modTime.Format("2006")
-			}
-		}
+	var existingYear string
+	if i := matcher.SubexpIndex("year"); i != -1 {
+		existingYear = match[i]
 	}
-	if year == "" {
-		year = timeNow().Format("2006")
+	year, err := h.year(filename, existingYear)
+	if err != nil {
+		return "", false, err
 	}
-
 	newHeader, err := h.render(filename, year)
 	if err != nil {
 		return "", false, fmt.Errorf("render header: %w", err)
 	}
-	modified := newHeader != header || cs != h.commentStyle
+	modified := newHeader != header || cs != style
+
+	return style.Render(newHeader), modified, nil
+}
+
+// in returns the time in the location used for year boundaries. If no
+// location is configured, the time is returned in its own location.
+func (h *Header) in(t time.Time) time.Time {
+	if h.location == nil {
+		return t
+	}
+	return t.In(h.location)
+}
+
+// now returns the current time from the clock.
+func (h *Header) now() time.Time {
+	if h.clock != nil {
+		return h.clock()
+	}
+	return timeNow()
+}
+
+// truncatedStart returns the start year to use when the history is truncated,
+// using the shallow policy. The visible start year is the creation year from
+// the truncated history.
+func (h *Header) truncatedStart(visible int, existingYears []int, err error) (int, error) {
+	switch h.shallowPolicy {
+	case ShallowTrustExisting:
+		if len(existingYears) > 0 && existingYears[0] < visible {
+			return existingYears[0], nil
+		}
+	case ShallowPreserve:
+		if len(existingYears) > 0 {
+			return existingYears[0], nil
+		}
+	case ShallowFail:
+		return 0, err
+	case ShallowIgnore:
+	}
+	return visible, nil
+}
 
-	return h.commentStyle.Render(newHeader), modified, nil
+// truncatedYears returns the modification years to use when the history is
+// truncated, using the shallow policy. Years from the existing license header
+// that are earlier than the visible history are kept.
+func (h *Header) truncatedYears(visible, existingYears []int, err error) ([]int, error) {
+	switch h.shallowPolicy {
+	case ShallowTrustExisting, ShallowPreserve:
+		var years []int
+		for _, y := range existingYears {
+			if len(visible) == 0 || y < visible[0] {
+				years = append(years, y)
+			}
+		}
+		return append(years, visible...), nil
+	case ShallowFail:
+		return nil, err
+	default:
+		return visible, nil
+	}
 }
 
 func (h *Header) render(filename, year string) (string, error) {
+	return h.renderWith(h.tmpl, filename, year, nil)
+}
+
+// renderWith renders the template. Values in overrides replace the values of
+// built-in and template variables.
+func (h *Header) renderWith(tmpl *template.Template, filename, year string, overrides map[string]string) (string, error) {
 	// Built-in variables.
 	m := map[string]any{
 		"author":   h.author,
@@ -424,14 +993,82 @@ func (h *Header) render(filename, year string) (string, error) {
 		"year":     year,
 	}
 	addVariables(m, h.variables)
+	for k, v := range overrides {
+		m[k] = v
+	}
 
 	var b bytes.Buffer
-	if err := h.tmpl.Execute(&b, m); err != nil {
+	if err := tmpl.Execute(&b, m); err != nil {
 		return "", fmt.Errorf("execute template: %w", err)
 	}
 	return b.String(), nil
 }
 
+// legacyMatcher creates the matcher for a legacy license header.
+func legacyMatcher(l LegacyHeader, authorRegexp *regexp.Regexp, variables map[string]*Var) (*regexp.Regexp, error) {
+	tmplStr, escape := l.Matcher, l.MatcherEscape
+	if tmplStr == "" {
+		tmplStr, escape = l.Template, true
+	}
+	if tmplStr == "" {
+		return nil, fmt.Errorf("missing template or matcher")
+	}
+	if _, ok := legacySeverityStrings[l.Severity]; !ok {
+		return nil, fmt.Errorf("invalid severity: %d", l.Severity)
+	}
+
+	switch {
+	case l.AuthorRegexp != "":
+		var err error
+		if authorRegexp, err = regexp.Compile(l.AuthorRegexp); err != nil {
+			return nil, fmt.Errorf("compile author regexp: %w", err)
+		}
+	case l.Author != "":
+		authorRegexp = regexp.MustCompile(regexp.QuoteMeta(l.Author))
+	}
+
+	t, err := template.New("").Funcs(tmplFuncMap).
+		Option("missingkey=error").Parse(tmplStr)
+	if err != nil {
+		return nil, fmt.Errorf("new matcher template: %w", err)
+	}
+	m, err := headerMatcher(t, escape, authorRegexp, variables)
+	if err != nil {
+		return nil, fmt.Errorf("create header matcher: %w", err)
+	}
+	return m, nil
+}
+
+// migrationMatcher creates the matcher for the previous license header of a
+// migration. The author and variables are captured using named capture
+// groups.
+func migrationMatcher(mg Migration, variables map[string]*Var) (*regexp.Regexp, error) {
+	tmplStr, escape := mg.FromMatcher, mg.FromMatcherEscape
+	if tmplStr == "" {
+		tmplStr, escape = mg.From, true
+	}
+	if tmplStr == "" {
+		return nil, fmt.Errorf("missing from template or matcher")
+	}
+
+	captureVars := make(map[string]*Var, len(variables))
+	for name, v := range variables {
+		captureVars[name] = &Var{Value: v.Value, Regexp: "(?P<" + name + ">" + v.Regexp + ")"}
+	}
+	authorRegexp := regexp.MustCompile(`(?P<author>[^\n]+)`)
+
+	t, err := template.New("").Funcs(tmplFuncMap).
+		Option("missingkey=error").Parse(tmplStr)
+	if err != nil {
+		return nil, fmt.Errorf("new matcher template: %w", err)
+	}
+	m, err := headerMatcher(t, escape, authorRegexp, captureVars)
+	if err != nil {
+		return nil, fmt.Errorf("create header matcher: %w", err)
+	}
+	return m, nil
+}
+
 func headerMatcher(tmpl *template.Template, escapeTmpl bool, authorRegexp *regexp.Regexp, variables map[string]*Var) (*regexp.Regexp, error) {
 	m := map[string]string{
 		"author":   "__VAR_author__",
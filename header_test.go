@@ -21,7 +21,9 @@
 package golicenser
 
 import (
+	"errors"
 	"regexp"
+	"strings"
 	"testing"
 	"text/template"
 	"time"
@@ -87,6 +89,46 @@ func TestYearModeString(t *testing.T) {
 	}
 }
 
+func TestParseLegacySeverity(t *testing.T) {
+	t.Parallel()
+
+	for ls, s := range legacySeverityStrings {
+		got, err := ParseLegacySeverity(strings.ToUpper(s))
+		if err != nil {
+			t.Errorf("ParseLegacySeverity(%q) err = %v", s, err)
+		}
+		if got != ls {
+			t.Errorf("ParseLegacySeverity(%q) = %v, want %v", s, got, ls)
+		}
+		if got := ls.String(); got != s {
+			t.Errorf("LegacySeverity(%d) = %s, want %s", ls, got, s)
+		}
+	}
+	if _, err := ParseLegacySeverity("invalid"); err == nil {
+		t.Errorf("ParseLegacySeverity(%q) err = nil, want error", "invalid")
+	}
+}
+
+func TestParseShallowPolicy(t *testing.T) {
+	t.Parallel()
+
+	for sp, s := range shallowPolicyStrings {
+		got, err := ParseShallowPolicy(strings.ToUpper(s))
+		if err != nil {
+			t.Errorf("ParseShallowPolicy(%q) err = %v", s, err)
+		}
+		if got != sp {
+			t.Errorf("ParseShallowPolicy(%q) = %v, want %v", s, got, sp)
+		}
+		if got := sp.String(); got != s {
+			t.Errorf("ShallowPolicy(%d) = %s, want %s", sp, got, s)
+		}
+	}
+	if _, err := ParseShallowPolicy("invalid"); err == nil {
+		t.Errorf("ParseShallowPolicy(%q) err = nil, want error", "invalid")
+	}
+}
+
 func TestParseCommentStyle(t *testing.T) {
 	t.Parallel()
 
@@ -106,6 +148,21 @@ func TestParseCommentStyle(t *testing.T) {
 			s:    CommentStyleBlock.String(),
 			want: CommentStyleBlock,
 		},
+		{
+			name: CommentStyleHash.String(),
+			s:    CommentStyleHash.String(),
+			want: CommentStyleHash,
+		},
+		{
+			name: CommentStyleDoubleDash.String(),
+			s:    CommentStyleDoubleDash.String(),
+			want: CommentStyleDoubleDash,
+		},
+		{
+			name: CommentStyleXML.String(),
+			s:    CommentStyleXML.String(),
+			want: CommentStyleXML,
+		},
 		{
 			name: "case insensitive",
 			s:    "BlOcK",
@@ -176,6 +233,24 @@ func TestCommentStyleRender(t *testing.T) {
 			want:  "/*\nLine 1\nLine 2\n*/\n",
 			style: CommentStyleBlock,
 		},
+		{
+			name:  "hash with blank line",
+			in:    "Line 1\n\nLine 2",
+			want:  "# Line 1\n#\n# Line 2\n",
+			style: CommentStyleHash,
+		},
+		{
+			name:  "double dash",
+			in:    "Line 1\nLine 2",
+			want:  "-- Line 1\n-- Line 2\n",
+			style: CommentStyleDoubleDash,
+		},
+		{
+			name:  "xml",
+			in:    "Line 1\nLine 2",
+			want:  "<!--\nLine 1\nLine 2\n-->\n",
+			style: CommentStyleXML,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -228,6 +303,24 @@ func TestCommentStyleParse(t *testing.T) {
 			want:  "Line 1\nLine 2",
 			style: CommentStyleBlock,
 		},
+		{
+			name:  "hash with blank line",
+			in:    "# Line 1\n#\n# Line 2\n",
+			want:  "Line 1\n\nLine 2",
+			style: CommentStyleHash,
+		},
+		{
+			name:  "double dash",
+			in:    "-- Line 1\n-- Line 2\n",
+			want:  "Line 1\nLine 2",
+			style: CommentStyleDoubleDash,
+		},
+		{
+			name:  "xml",
+			in:    "<!--\nLine 1\nLine 2\n-->\n",
+			want:  "Line 1\nLine 2",
+			style: CommentStyleXML,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -381,6 +474,86 @@ func TestNewHeader(t *testing.T) {
 				Author:   "Joshua Sing",
 			},
 		},
+		{
+			name: "with legacy headers",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				Legacy: []LegacyHeader{
+					{Template: LicenseMIT, Severity: LegacyMigrate},
+					{Matcher: "Copyright {{.year}} (Old|Older) Author", Severity: LegacyWarn},
+					{Template: "Copyright {{.year}} {{.author}}", AuthorRegexp: "(Someone|Else)"},
+				},
+			},
+		},
+		{
+			name: "with migrations",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				Variables: map[string]*Var{
+					"project": {Value: "golicenser"},
+				},
+				Migrations: []Migration{
+					{From: LicenseMIT, To: LicenseApache2},
+					{FromMatcher: "{{.project}} is (c) {{.year}}", Carry: []string{"year", "project"}},
+				},
+			},
+		},
+		{
+			name: "migration without from",
+			header: HeaderOpts{
+				Template:   "Copyright (c) {{.year}} {{.author}}",
+				Author:     "Joshua Sing",
+				Migrations: []Migration{{To: LicenseMIT}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "migration unknown carry field",
+			header: HeaderOpts{
+				Template:   "Copyright (c) {{.year}} {{.author}}",
+				Author:     "Joshua Sing",
+				Migrations: []Migration{{From: LicenseMIT, Carry: []string{"holder"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "migration invalid to template",
+			header: HeaderOpts{
+				Template:   "Copyright (c) {{.year}} {{.author}}",
+				Author:     "Joshua Sing",
+				Migrations: []Migration{{From: LicenseMIT, To: "{{.unknown}}"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "legacy header without template",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				Legacy:   []LegacyHeader{{Severity: LegacyWarn}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "legacy header invalid severity",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				Legacy:   []LegacyHeader{{Template: "test", Severity: 10}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "legacy header invalid author regexp",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				Legacy:   []LegacyHeader{{Template: "{{.author}}", AuthorRegexp: "(test"}},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -420,6 +593,54 @@ func TestHeaderCreate(t *testing.T) {
 			},
 			want: "/*\nCopyright (c) 2025 Joshua Sing\n*/\n",
 		},
+		{
+			name: "clock",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				Clock: func() time.Time {
+					return time.Date(2031, time.March, 1, 0, 0, 0, 0, time.UTC)
+				},
+			},
+			want: "// Copyright (c) 2031 Joshua Sing\n",
+		},
+		{
+			name: "last modified year from history",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				YearMode: YearModeLastModified,
+				History:  testHistory{modified: yearTime(2023)},
+			},
+			want: "// Copyright (c) 2023 Joshua Sing\n",
+		},
+		{
+			name: "git range from history",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				YearMode: YearModeGitRange,
+				History: testHistory{
+					created:  yearTime(2019),
+					modified: yearTime(2023),
+				},
+			},
+			want: "// Copyright (c) 2019-2023 Joshua Sing\n",
+		},
+		{
+			name: "git modified list from history",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				YearMode: YearModeGitModifiedList,
+				History: testHistory{
+					created:  yearTime(2019),
+					modified: yearTime(2023),
+					modTimes: []time.Time{yearTime(2019), yearTime(2021), yearTime(2023)},
+				},
+			},
+			want: "// Copyright (c) 2019, 2021, 2023 Joshua Sing\n",
+		},
 		{
 			name: "use filename",
 			header: HeaderOpts{
@@ -462,6 +683,39 @@ func TestHeaderCreate(t *testing.T) {
 			},
 			want: "// project by human\n",
 		},
+		{
+			name: "SPDX",
+			header: HeaderOpts{
+				SPDX:   "apache-2.0",
+				Author: "Joshua Sing",
+			},
+			want: "// Copyright (c) 2025 Joshua Sing\n// SPDX-License-Identifier: Apache-2.0\n",
+		},
+		{
+			name: "SPDX with template",
+			header: HeaderOpts{
+				Template: "SPDX-License-Identifier: {{.spdx}}\nCopyright {{.year}} {{.author}}",
+				SPDX:     "MIT OR Apache-2.0",
+				Author:   "Joshua Sing",
+			},
+			want: "// SPDX-License-Identifier: MIT OR Apache-2.0\n// Copyright 2025 Joshua Sing\n",
+		},
+		{
+			name: "SPDX invalid",
+			header: HeaderOpts{
+				SPDX:   "Not-A-License",
+				Author: "Joshua Sing",
+			},
+			wantErr: true,
+		},
+		{
+			name: "spdx variable without SPDX",
+			header: HeaderOpts{
+				Template: "SPDX-License-Identifier: {{.spdx}}",
+				Author:   "Joshua Sing",
+			},
+			wantErr: true,
+		},
 		{
 			name: "MIT",
 			header: HeaderOpts{
@@ -511,6 +765,161 @@ func TestHeaderCreate(t *testing.T) {
 	}
 }
 
+func TestHeaderMatches(t *testing.T) {
+	t.Parallel()
+
+	h, err := NewHeader(HeaderOpts{
+		SPDX:   "MIT",
+		Author: "Joshua Sing",
+	})
+	if err != nil {
+		t.Fatalf("NewHeader err = %v", err)
+	}
+	dual, err := NewHeader(HeaderOpts{
+		SPDX:   "MIT OR Apache-2.0",
+		Author: "Joshua Sing",
+	})
+	if err != nil {
+		t.Fatalf("NewHeader err = %v", err)
+	}
+
+	mitFull := CommentStyleLine.Render(strings.NewReplacer(
+		"{{.year}}", "2020",
+		"{{.author}}", "Joshua Sing",
+	).Replace(LicenseMIT))
+
+	tests := []struct {
+		name   string
+		h      *Header
+		header string
+		want   bool
+	}{
+		{
+			name:   "short-form",
+			h:      h,
+			header: "// Copyright (c) 2022 Joshua Sing\n// SPDX-License-Identifier: MIT\n",
+			want:   true,
+		},
+		{
+			name:   "full text equivalent",
+			h:      h,
+			header: mitFull,
+			want:   true,
+		},
+		{
+			name:   "different license",
+			h:      h,
+			header: "// Copyright (c) 2022 Joshua Sing\n// SPDX-License-Identifier: Apache-2.0\n",
+		},
+		{
+			name:   "different author",
+			h:      h,
+			header: strings.ReplaceAll(mitFull, "Joshua Sing", "Someone Else"),
+		},
+		{
+			name:   "dual license full text",
+			h:      dual,
+			header: mitFull,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.h.Matches(tt.header); got != tt.want {
+				t.Errorf("h.Matches(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeaderMigrate(t *testing.T) {
+	t.Parallel()
+
+	replacer := strings.NewReplacer("{{.year}}", "2019", "{{.author}}", "Old Holder")
+	mit := CommentStyleLine.Render(replacer.Replace(LicenseMIT))
+
+	tests := []struct {
+		name         string
+		header       HeaderOpts
+		existing     string
+		want         string
+		wantModified bool
+	}{
+		{
+			name: "MIT to Apache-2.0",
+			header: HeaderOpts{
+				Template:     LicenseApache2,
+				Author:       "Joshua Sing",
+				AuthorRegexp: ".+",
+				YearMode:     YearModePreserve,
+				Migrations:   []Migration{{From: LicenseMIT}},
+			},
+			existing:     mit,
+			want:         CommentStyleLine.Render(replacer.Replace(LicenseApache2)),
+			wantModified: true,
+		},
+		{
+			name: "carry year only",
+			header: HeaderOpts{
+				Template:   "Copyright (c) {{.year}} {{.author}}\nSPDX-License-Identifier: Apache-2.0",
+				Author:     "Joshua Sing",
+				YearMode:   YearModePreserveThisYearRange,
+				Migrations: []Migration{{From: LicenseMIT, Carry: []string{"year"}}},
+			},
+			existing:     mit,
+			want:         "// Copyright (c) 2019-2025 Joshua Sing\n// SPDX-License-Identifier: Apache-2.0\n",
+			wantModified: true,
+		},
+		{
+			name: "carry variable",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}\nPart of {{.project}}.",
+				Author:   "Joshua Sing",
+				Variables: map[string]*Var{
+					"project": {Value: "golicenser", Regexp: "[a-z]+"},
+				},
+				YearMode: YearModePreserve,
+				Migrations: []Migration{{
+					From:  "{{.project}} (c) {{.year}}",
+					Carry: []string{"year", "project"},
+				}},
+			},
+			existing:     "// oldproject (c) 2020\n",
+			want:         "// Copyright (c) 2020 Joshua Sing\n// Part of oldproject.\n",
+			wantModified: true,
+		},
+		{
+			name: "not matched",
+			header: HeaderOpts{
+				Template:   "Copyright (c) {{.year}} {{.author}}",
+				Author:     "Joshua Sing",
+				Migrations: []Migration{{From: LicenseMIT}},
+			},
+			existing: "// Copyright (c) 2020 Joshua Sing\n",
+			want:     "// Copyright (c) 2020 Joshua Sing\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h, err := NewHeader(tt.header)
+			if err != nil {
+				t.Fatalf("NewHeader err = %v", err)
+			}
+			got, modified, err := h.Migrate("test.go", tt.existing)
+			if err != nil {
+				t.Fatalf("h.Migrate err = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("h.Migrate() = %q, want %q", got, tt.want)
+			}
+			if modified != tt.wantModified {
+				t.Errorf("h.Migrate() modified = %v, want %v", modified, tt.wantModified)
+			}
+		})
+	}
+}
+
 func TestHeaderUpdate(t *testing.T) {
 	t.Parallel()
 
@@ -554,6 +963,114 @@ func TestHeaderUpdate(t *testing.T) {
 			want:         "// Copyright (c) 2025 Joshua Sing\n",
 			wantModified: true,
 		},
+		{
+			name: "last modified year from history",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				YearMode: YearModeLastModified,
+				History:  testHistory{modified: yearTime(2023)},
+			},
+			existing:     "// Copyright (c) 2001 Joshua Sing\n",
+			want:         "// Copyright (c) 2023 Joshua Sing\n",
+			wantModified: true,
+		},
+		{
+			name: "preserve modified range from history",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				YearMode: YearModePreserveModifiedRange,
+				History:  testHistory{modified: yearTime(2023)},
+			},
+			existing:     "// Copyright (c) 2001 Joshua Sing\n",
+			want:         "// Copyright (c) 2001-2023 Joshua Sing\n",
+			wantModified: true,
+		},
+		{
+			name: "git range from history",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				YearMode: YearModeGitRange,
+				History: testHistory{
+					created:  yearTime(2020),
+					modified: yearTime(2024),
+				},
+			},
+			existing:     "// Copyright (c) 2001 Joshua Sing\n",
+			want:         "// Copyright (c) 2020-2024 Joshua Sing\n",
+			wantModified: true,
+		},
+		{
+			name: "git modified list from history",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				YearMode: YearModeGitModifiedList,
+				History: testHistory{
+					created:  yearTime(2020),
+					modified: yearTime(2022),
+					modTimes: []time.Time{yearTime(2020), yearTime(2020), yearTime(2022)},
+				},
+			},
+			existing:     "// Copyright (c) 2001 Joshua Sing\n",
+			want:         "// Copyright (c) 2020, 2022 Joshua Sing\n",
+			wantModified: true,
+		},
+		{
+			name: "git modified list from dirty history",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				YearMode: YearModeGitModifiedList,
+				History: testHistory{
+					created:  yearTime(2020),
+					modified: yearTime(2024),
+					modTimes: []time.Time{yearTime(2020), yearTime(2022)},
+					dirty:    true,
+				},
+			},
+			existing:     "// Copyright (c) 2001 Joshua Sing\n",
+			want:         "// Copyright (c) 2020, 2022, 2024 Joshua Sing\n",
+			wantModified: true,
+		},
+		{
+			name: "preserve years verbatim",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				YearMode: YearModePreserve,
+			},
+			existing: "// Copyright (c) 2012 – 2014,2018 Joshua Sing\n",
+			want:     "// Copyright (c) 2012 – 2014,2018 Joshua Sing\n",
+		},
+		{
+			name: "normalise years in list format",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				YearMode: yearPolicy{
+					start:  []yearSource{yearSourcePreserve},
+					end:    []yearSource{yearSourcePreserve},
+					format: yearFormatCompactList,
+				}.encode(),
+			},
+			existing:     "// Copyright (c) 2012 – 2014,2018 Joshua Sing\n",
+			want:         "// Copyright (c) 2012-2014, 2018 Joshua Sing\n",
+			wantModified: true,
+		},
+		{
+			name: "present year range to this year range",
+			header: HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				YearMode: YearModePreserveThisYearRange,
+			},
+			existing:     "// Copyright (c) 2016-present Joshua Sing\n",
+			want:         "// Copyright (c) 2016-2025 Joshua Sing\n",
+			wantModified: true,
+		},
 		{
 			name: "change block comment to line comment",
 			header: HeaderOpts{
@@ -718,6 +1235,169 @@ SOFTWARE.
 	}
 }
 
+func TestHeaderShallowPolicy(t *testing.T) {
+	t.Parallel()
+
+	truncated := testHistory{
+		created:   yearTime(2023),
+		modified:  yearTime(2024),
+		modTimes:  []time.Time{yearTime(2023), yearTime(2024)},
+		truncated: true,
+	}
+	tests := []struct {
+		name     string
+		policy   ShallowPolicy
+		yearMode YearMode
+		existing string
+
+		want    string
+		wantErr bool
+	}{
+		{
+			name:     "trust existing earlier year",
+			policy:   ShallowTrustExisting,
+			yearMode: YearModeGitRange,
+			existing: "// Copyright (c) 2019 Joshua Sing\n",
+			want:     "// Copyright (c) 2019-2024 Joshua Sing\n",
+		},
+		{
+			name:     "trust existing later year",
+			policy:   ShallowTrustExisting,
+			yearMode: YearModeGitRange,
+			existing: "// Copyright (c) 2024 Joshua Sing\n",
+			want:     "// Copyright (c) 2023-2024 Joshua Sing\n",
+		},
+		{
+			name:     "preserve existing year",
+			policy:   ShallowPreserve,
+			yearMode: YearModeGitRange,
+			existing: "// Copyright (c) 2024 Joshua Sing\n",
+			want:     "// Copyright (c) 2024 Joshua Sing\n",
+		},
+		{
+			name:     "ignore truncation",
+			policy:   ShallowIgnore,
+			yearMode: YearModeGitRange,
+			existing: "// Copyright (c) 2019 Joshua Sing\n",
+			want:     "// Copyright (c) 2023-2024 Joshua Sing\n",
+		},
+		{
+			name:     "fail",
+			policy:   ShallowFail,
+			yearMode: YearModeGitRange,
+			existing: "// Copyright (c) 2019 Joshua Sing\n",
+			wantErr:  true,
+		},
+		{
+			name:     "trust existing modified list",
+			policy:   ShallowTrustExisting,
+			yearMode: YearModeGitModifiedList,
+			existing: "// Copyright (c) 2018, 2020, 2024 Joshua Sing\n",
+			want:     "// Copyright (c) 2018, 2020, 2023, 2024 Joshua Sing\n",
+		},
+		{
+			name:     "fail modified list",
+			policy:   ShallowFail,
+			yearMode: YearModeGitModifiedList,
+			existing: "// Copyright (c) 2018, 2020 Joshua Sing\n",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h, err := NewHeader(HeaderOpts{
+				Template:      "Copyright (c) {{.year}} {{.author}}",
+				Author:        "Joshua Sing",
+				YearMode:      tt.yearMode,
+				History:       truncated,
+				ShallowPolicy: tt.policy,
+			})
+			if err != nil {
+				t.Fatalf("NewHeader() err = %v", err)
+			}
+			got, _, err := h.Update("test.go", tt.existing)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("h.Update() err = %v, want err %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrHistoryTruncated) {
+				t.Errorf("h.Update() err = %v, want ErrHistoryTruncated", err)
+			}
+			if got != tt.want {
+				t.Errorf("h.Update() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeaderCreateShallowFail(t *testing.T) {
+	t.Parallel()
+
+	h, err := NewHeader(HeaderOpts{
+		Template: "Copyright (c) {{.year}} {{.author}}",
+		Author:   "Joshua Sing",
+		YearMode: YearModeGitRange,
+		History: testHistory{
+			created:   yearTime(2023),
+			modified:  yearTime(2024),
+			truncated: true,
+		},
+		ShallowPolicy: ShallowFail,
+	})
+	if err != nil {
+		t.Fatalf("NewHeader() err = %v", err)
+	}
+	if _, err = h.Create("test.go"); !errors.Is(err, ErrHistoryTruncated) {
+		t.Errorf("h.Create() err = %v, want ErrHistoryTruncated", err)
+	}
+}
+
+func TestHeaderTimezone(t *testing.T) {
+	t.Parallel()
+
+	// 2023-12-31 23:30 in New York is 2024-01-01 04:30 UTC.
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("load location: %v", err)
+	}
+	modified := time.Date(2023, time.December, 31, 23, 30, 0, 0, newYork)
+
+	tests := []struct {
+		timezone string
+		want     string
+		wantErr  bool
+	}{
+		{timezone: "UTC", want: "// Copyright (c) 2024 Joshua Sing\n"},
+		{timezone: "America/New_York", want: "// Copyright (c) 2023 Joshua Sing\n"},
+		{timezone: "Mars/Olympus_Mons", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			t.Parallel()
+			h, err := NewHeader(HeaderOpts{
+				Template: "Copyright (c) {{.year}} {{.author}}",
+				Author:   "Joshua Sing",
+				YearMode: YearModeLastModified,
+				History:  testHistory{modified: modified},
+				Timezone: tt.timezone,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewHeader() err = %v, want err %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got, _, err := h.Update("test.go", "// Copyright (c) 2001 Joshua Sing\n")
+			if err != nil {
+				t.Fatalf("h.Update() err = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("h.Update() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestHeaderMatcher(t *testing.T) {
 	t.Parallel()
 
@@ -762,6 +1442,26 @@ func TestHeaderMatcher(t *testing.T) {
 					input:     "Copyright (c) 2000 Test\nFile: header_test.go",
 					wantMatch: true,
 				},
+				{
+					name:      "year range",
+					input:     "Copyright (c) 2015-2019 Test\nFile: header_test.go",
+					wantMatch: true,
+				},
+				{
+					name:      "spaced en dash year range",
+					input:     "Copyright (c) 2015 – 2019 Test\nFile: header_test.go",
+					wantMatch: true,
+				},
+				{
+					name:      "present year range",
+					input:     "Copyright (c) 2016-present Test\nFile: header_test.go",
+					wantMatch: true,
+				},
+				{
+					name:      "mixed year list",
+					input:     "Copyright (c) 2012-2014, 2018,2020 Test\nFile: header_test.go",
+					wantMatch: true,
+				},
 			},
 		},
 		{
@@ -0,0 +1,427 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golicenser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotInHistory is returned by a History when a file is not in the history.
+var ErrNotInHistory = errors.New("file not in history")
+
+// ErrHistoryTruncated is returned by a History when the history of a file is
+// truncated, such as in a shallow Git clone. It is returned together with the
+// visible history.
+var ErrHistoryTruncated = errors.New("history is truncated (shallow clone)")
+
+// History provides the modification history of files. It is used by year modes
+// to determine when a file was created and modified.
+type History interface {
+	// Created returns the time the file was created. If the history is
+	// truncated, the earliest visible time is returned with an error wrapping
+	// ErrHistoryTruncated.
+	Created(filename string) (time.Time, error)
+
+	// Modified returns the time the file was last modified, including
+	// uncommitted modifications.
+	Modified(filename string) (time.Time, error)
+
+	// ModTimes returns the times of all committed modifications of the file,
+	// oldest first. If the history is truncated, the visible modification
+	// times are returned with an error wrapping ErrHistoryTruncated.
+	ModTimes(filename string) ([]time.Time, error)
+
+	// IsDirty returns whether the file has uncommitted modifications.
+	IsDirty(filename string) (bool, error)
+}
+
+// History sources.
+const (
+	// HistorySourceGit reads history from Git, falling back to the filesystem
+	// for files that are not tracked or have been modified locally.
+	HistorySourceGit = "git"
+
+	// HistorySourceFS reads modification times from the filesystem only.
+	HistorySourceFS = "fs"
+
+	// HistorySourceJSON reads history from a precomputed JSON history file.
+	HistorySourceJSON = "json"
+)
+
+// HistoryOpts are the options for creating a History.
+type HistoryOpts struct {
+	// Source is the history source: "git", "fs" or "json". If empty, the JSON
+	// source is used if File is set, otherwise the Git source is used.
+	Source string
+
+	// File is the JSON history file used by the JSON source.
+	File string
+
+	// Git are the options for the Git source.
+	Git GitHistoryOpts
+}
+
+// NewHistory creates the History for a history source.
+func NewHistory(opts HistoryOpts) (History, error) {
+	source := opts.Source
+	if source == "" {
+		source = HistorySourceGit
+		if opts.File != "" {
+			source = HistorySourceJSON
+		}
+	}
+	switch source {
+	case HistorySourceGit:
+		return NewGitHistory(opts.Git)
+	case HistorySourceFS:
+		return FSHistory(), nil
+	case HistorySourceJSON:
+		if opts.File == "" {
+			return nil, errors.New("json history requires a history file")
+		}
+		return LoadJSONHistory(opts.File)
+	default:
+		return nil, fmt.Errorf("invalid history source: %q", source)
+	}
+}
+
+// fsHistory is a History using file modification times from the filesystem.
+type fsHistory struct{}
+
+// FSHistory returns a History that uses the file modification time from the
+// filesystem. Filesystems do not reliably record the creation time of files,
+// so the modification time is used as the creation time.
+func FSHistory() History {
+	return fsHistory{}
+}
+
+func (fsHistory) Created(filename string) (time.Time, error) {
+	return fsModTime(filename)
+}
+
+func (fsHistory) Modified(filename string) (time.Time, error) {
+	return fsModTime(filename)
+}
+
+func (fsHistory) ModTimes(filename string) ([]time.Time, error) {
+	modTime, err := fsModTime(filename)
+	if err != nil {
+		return nil, err
+	}
+	return []time.Time{modTime}, nil
+}
+
+func (fsHistory) IsDirty(string) (bool, error) {
+	return false, nil
+}
+
+// fsModTime returns the file modification time from disk.
+func fsModTime(filename string) (time.Time, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// jsonHistoryVersion is the current JSON history file version.
+const jsonHistoryVersion = 1
+
+// jsonHistoryFile is the JSON history file format.
+type jsonHistoryFile struct {
+	Version int `json:"version"`
+
+	// Files is the history of each file, keyed by the slash-separated path of
+	// the file relative to the directory containing the history file.
+	Files map[string]*jsonFileHistory `json:"files"`
+}
+
+// jsonFileHistory is the history of a file in a JSON history file.
+type jsonFileHistory struct {
+	Created   *time.Time  `json:"created,omitempty"`
+	Modified  time.Time   `json:"modified"`
+	ModTimes  []time.Time `json:"mod-times,omitempty"`
+	Dirty     bool        `json:"dirty,omitempty"`
+	Truncated bool        `json:"truncated,omitempty"`
+}
+
+// jsonHistory is a History read from a JSON history file.
+type jsonHistory struct {
+	root  string
+	files map[string]*jsonFileHistory
+}
+
+// LoadJSONHistory loads a History from a JSON history file. The paths in the
+// history file are relative to the directory containing the file. The
+// filesystem is never accessed for history, making it suitable for hermetic
+// build sandboxes without a Git repository.
+func LoadJSONHistory(filename string) (History, error) {
+	//nolint:gosec // Reading user-defined file.
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("open history file: %w", err)
+	}
+	defer f.Close()
+
+	root, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONHistory(f, root)
+}
+
+// NewJSONHistory reads a JSON history with paths relative to root.
+func NewJSONHistory(r io.Reader, root string) (History, error) {
+	var hf jsonHistoryFile
+	if err := json.NewDecoder(r).Decode(&hf); err != nil {
+		return nil, fmt.Errorf("decode history: %w", err)
+	}
+	if hf.Version != jsonHistoryVersion {
+		return nil, fmt.Errorf("unsupported history version %d (want %d)",
+			hf.Version, jsonHistoryVersion)
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	for path, fh := range hf.Files {
+		if fh == nil {
+			return nil, fmt.Errorf("%s: missing history", path)
+		}
+		if fh.Created != nil && fh.Created.IsZero() {
+			return nil, fmt.Errorf("%s: invalid created time", path)
+		}
+		if fh.Modified.IsZero() {
+			return nil, fmt.Errorf("%s: missing modified time", path)
+		}
+		if !slices.IsSortedFunc(fh.ModTimes, time.Time.Compare) {
+			return nil, fmt.Errorf("%s: mod-times must be sorted oldest first", path)
+		}
+	}
+	return &jsonHistory{root: root, files: hf.Files}, nil
+}
+
+func (h *jsonHistory) file(filename string) (*jsonFileHistory, error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	path, err := relPath(h.root, abs)
+	if err != nil {
+		return nil, err
+	}
+	fh, ok := h.files[path]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", filename, ErrNotInHistory)
+	}
+	return fh, nil
+}
+
+func (h *jsonHistory) Created(filename string) (time.Time, error) {
+	fh, err := h.file(filename)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if fh.Created == nil {
+		return time.Time{}, fmt.Errorf("%s: %w", filename, ErrNotInHistory)
+	}
+	if fh.Truncated {
+		return *fh.Created, fmt.Errorf("%s: %w", filename, ErrHistoryTruncated)
+	}
+	return *fh.Created, nil
+}
+
+func (h *jsonHistory) Modified(filename string) (time.Time, error) {
+	fh, err := h.file(filename)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return fh.Modified, nil
+}
+
+func (h *jsonHistory) ModTimes(filename string) ([]time.Time, error) {
+	fh, err := h.file(filename)
+	if err != nil {
+		return nil, err
+	}
+	if len(fh.ModTimes) == 0 {
+		return nil, fmt.Errorf("%s: %w", filename, ErrNotInHistory)
+	}
+	if fh.Truncated {
+		return slices.Clone(fh.ModTimes), fmt.Errorf("%s: %w", filename, ErrHistoryTruncated)
+	}
+	return slices.Clone(fh.ModTimes), nil
+}
+
+func (h *jsonHistory) IsDirty(filename string) (bool, error) {
+	fh, err := h.file(filename)
+	if err != nil {
+		return false, err
+	}
+	return fh.Dirty, nil
+}
+
+// WriteJSONHistory writes the history of the files as a JSON history file, with
+// paths relative to root. Files that are not in the history are skipped.
+func WriteJSONHistory(w io.Writer, h History, root string, filenames []string) error {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	hf := jsonHistoryFile{
+		Version: jsonHistoryVersion,
+		Files:   make(map[string]*jsonFileHistory, len(filenames)),
+	}
+	for _, filename := range filenames {
+		abs, err := filepath.Abs(filename)
+		if err != nil {
+			return err
+		}
+		path, err := relPath(root, abs)
+		if err != nil {
+			return err
+		}
+
+		modified, err := h.Modified(filename)
+		if err != nil {
+			if errors.Is(err, ErrNotInHistory) {
+				continue
+			}
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+		fh := &jsonFileHistory{Modified: modified}
+		if created, err := h.Created(filename); err == nil || errors.Is(err, ErrHistoryTruncated) {
+			fh.Created = &created
+			fh.Truncated = err != nil
+		}
+		if modTimes, err := h.ModTimes(filename); err == nil || errors.Is(err, ErrHistoryTruncated) {
+			fh.ModTimes = modTimes
+			fh.Truncated = fh.Truncated || err != nil
+		}
+		if dirty, err := h.IsDirty(filename); err == nil {
+			fh.Dirty = dirty
+		}
+		hf.Files[path] = fh
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(hf)
+}
+
+// relPath returns the slash-separated path of abs relative to root, or an
+// error if abs is not inside root.
+func relPath(root, abs string) (string, error) {
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return "", err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside of %s", abs, root)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// memoHistory is a History that memoizes the results of another History for
+// each file. The analysis driver runs a pass for each package, so the memo
+// shares the results between passes.
+type memoHistory struct {
+	h     History
+	files sync.Map // map[string]*memoEntry
+}
+
+// memoEntry is the memoized history of a file.
+type memoEntry struct {
+	created  memoValue[time.Time]
+	modified memoValue[time.Time]
+	modTimes memoValue[[]time.Time]
+	dirty    memoValue[bool]
+}
+
+// memoValue is a memoized result.
+type memoValue[T any] struct {
+	once sync.Once
+	v    T
+	err  error
+}
+
+// get returns the memoized result, calling fn if there is none.
+func (m *memoValue[T]) get(fn func() (T, error)) (T, error) {
+	metrics.historyLookups.Add(1)
+	hit := true
+	m.once.Do(func() {
+		hit = false
+		m.v, m.err = fn()
+	})
+	if hit {
+		metrics.historyHits.Add(1)
+	}
+	return m.v, m.err
+}
+
+// newMemoHistory returns a History memoizing the results of h.
+func newMemoHistory(h History) *memoHistory {
+	if m, ok := h.(*memoHistory); ok {
+		return m
+	}
+	return &memoHistory{h: h}
+}
+
+func (m *memoHistory) entry(filename string) *memoEntry {
+	v, _ := m.files.LoadOrStore(filename, &memoEntry{})
+	return v.(*memoEntry)
+}
+
+func (m *memoHistory) Created(filename string) (time.Time, error) {
+	return m.entry(filename).created.get(func() (time.Time, error) {
+		return m.h.Created(filename)
+	})
+}
+
+func (m *memoHistory) Modified(filename string) (time.Time, error) {
+	return m.entry(filename).modified.get(func() (time.Time, error) {
+		return m.h.Modified(filename)
+	})
+}
+
+func (m *memoHistory) ModTimes(filename string) ([]time.Time, error) {
+	modTimes, err := m.entry(filename).modTimes.get(func() ([]time.Time, error) {
+		return m.h.ModTimes(filename)
+	})
+	// The memoized slice is shared, so callers get their own copy.
+	return slices.Clone(modTimes), err
+}
+
+func (m *memoHistory) IsDirty(filename string) (bool, error) {
+	return m.entry(filename).dirty.get(func() (bool, error) {
+		return m.h.IsDirty(filename)
+	})
+}
@@ -0,0 +1,277 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golicenser
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testHistory is a History returning the same history for all files.
+type testHistory struct {
+	created   time.Time
+	modified  time.Time
+	modTimes  []time.Time
+	dirty     bool
+	truncated bool
+}
+
+func (h testHistory) Created(string) (time.Time, error) {
+	if h.truncated {
+		return h.created, ErrHistoryTruncated
+	}
+	return h.created, nil
+}
+
+func (h testHistory) Modified(string) (time.Time, error) {
+	return h.modified, nil
+}
+
+func (h testHistory) ModTimes(string) ([]time.Time, error) {
+	if h.truncated {
+		return h.modTimes, ErrHistoryTruncated
+	}
+	return h.modTimes, nil
+}
+
+func (h testHistory) IsDirty(string) (bool, error) {
+	return h.dirty, nil
+}
+
+// yearTime returns a time in the given year.
+func yearTime(year int) time.Time {
+	return time.Date(year, time.June, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestNewHistory(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		source  string
+		file    string
+		want    History
+		wantErr bool
+	}{
+		{source: "", want: GitHistory()},
+		{source: HistorySourceGit, want: GitHistory()},
+		{source: HistorySourceFS, want: FSHistory()},
+		{source: HistorySourceJSON, wantErr: true},
+		{source: "", file: "testdata/missing.json", wantErr: true},
+		{source: "svn", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			t.Parallel()
+			got, err := NewHistory(HistoryOpts{Source: tt.source, File: tt.file})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewHistory(%q, %q) err = %v, want err %v",
+					tt.source, tt.file, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NewHistory(%q, %q) = %T, want %T", tt.source, tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONHistory(t *testing.T) {
+	t.Parallel()
+
+	const history = `{
+  "version": 1,
+  "files": {
+    "a.go": {
+      "created": "2021-06-01T00:00:00Z",
+      "modified": "2025-06-01T00:00:00Z",
+      "mod-times": ["2021-06-01T00:00:00Z", "2023-06-01T00:00:00Z"],
+      "dirty": true
+    },
+    "pkg/untracked.go": {
+      "modified": "2024-06-01T00:00:00Z"
+    }
+  }
+}`
+	root := t.TempDir()
+	h, err := NewJSONHistory(strings.NewReader(history), root)
+	if err != nil {
+		t.Fatalf("NewJSONHistory() err = %v", err)
+	}
+
+	a := filepath.Join(root, "a.go")
+	if got, err := h.Created(a); err != nil || !got.Equal(yearTime(2021)) {
+		t.Errorf("Created(a.go) = %v, %v, want %v", got, err, yearTime(2021))
+	}
+	if got, err := h.Modified(a); err != nil || got.Year() != 2025 {
+		t.Errorf("Modified(a.go) = %v, %v, want 2025", got, err)
+	}
+	modTimes, err := h.ModTimes(a)
+	if err != nil {
+		t.Errorf("ModTimes(a.go) err = %v", err)
+	}
+	if want := []time.Time{yearTime(2021), yearTime(2023)}; !slices.EqualFunc(modTimes, want, time.Time.Equal) {
+		t.Errorf("ModTimes(a.go) = %v, want %v", modTimes, want)
+	}
+	if dirty, err := h.IsDirty(a); err != nil || !dirty {
+		t.Errorf("IsDirty(a.go) = %v, %v, want true", dirty, err)
+	}
+
+	untracked := filepath.Join(root, "pkg", "untracked.go")
+	if _, err := h.Created(untracked); !errors.Is(err, ErrNotInHistory) {
+		t.Errorf("Created(pkg/untracked.go) err = %v, want ErrNotInHistory", err)
+	}
+	if got, err := h.Modified(untracked); err != nil || got.Year() != 2024 {
+		t.Errorf("Modified(pkg/untracked.go) = %v, %v, want 2024", got, err)
+	}
+
+	if _, err := h.Modified(filepath.Join(root, "missing.go")); !errors.Is(err, ErrNotInHistory) {
+		t.Errorf("Modified(missing.go) err = %v, want ErrNotInHistory", err)
+	}
+	if _, err := h.Modified(filepath.Join(root, "..", "outside.go")); err == nil {
+		t.Errorf("Modified(../outside.go) err = nil, want error")
+	}
+}
+
+func TestNewJSONHistoryInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		history string
+	}{
+		{name: "invalid json", history: "{"},
+		{name: "unsupported version", history: `{"version": 2}`},
+		{name: "null file", history: `{"version": 1, "files": {"a.go": null}}`},
+		{
+			name:    "unsorted mod-times",
+			history: `{"version": 1, "files": {"a.go": {"modified": "2024-01-01T00:00:00Z", "mod-times": ["2024-01-01T00:00:00Z", "2023-01-01T00:00:00Z"]}}}`,
+		},
+		{
+			name:    "missing modified",
+			history: `{"version": 1, "files": {"a.go": {"created": "2023-01-01T00:00:00Z"}}}`,
+		},
+		{
+			name:    "zero created",
+			history: `{"version": 1, "files": {"a.go": {"created": "0001-01-01T00:00:00Z", "modified": "2024-01-01T00:00:00Z"}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := NewJSONHistory(strings.NewReader(tt.history), "."); err == nil {
+				t.Errorf("NewJSONHistory() err = nil, want error")
+			}
+		})
+	}
+}
+
+func TestLoadJSONHistoryMissingModified(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "history.json")
+	content := `{
+  "version": 1,
+  "files": {
+    "pkg/a.go": {
+      "created": "2021-03-01T00:00:00Z",
+      "mod-times": ["2021-03-01T00:00:00Z"]
+    }
+  }
+}
+`
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadJSONHistory(filename)
+	if err == nil || !strings.Contains(err.Error(), "pkg/a.go") {
+		t.Errorf("LoadJSONHistory() err = %v, want error naming pkg/a.go", err)
+	}
+}
+
+func TestWriteJSONHistory(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	want := testHistory{
+		created:  yearTime(2020),
+		modified: yearTime(2025),
+		modTimes: []time.Time{yearTime(2020), yearTime(2022)},
+		dirty:    true,
+	}
+	a := filepath.Join(root, "pkg", "a.go")
+
+	var buf bytes.Buffer
+	if err := WriteJSONHistory(&buf, want, root, []string{a}); err != nil {
+		t.Fatalf("WriteJSONHistory() err = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "history.json"), buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := LoadJSONHistory(filepath.Join(root, "history.json"))
+	if err != nil {
+		t.Fatalf("LoadJSONHistory() err = %v", err)
+	}
+	if got, err := h.Created(a); err != nil || !got.Equal(want.created) {
+		t.Errorf("Created() = %v, %v, want %v", got, err, want.created)
+	}
+	if got, err := h.Modified(a); err != nil || !got.Equal(want.modified) {
+		t.Errorf("Modified() = %v, %v, want %v", got, err, want.modified)
+	}
+	if got, err := h.ModTimes(a); err != nil || !slices.EqualFunc(got, want.modTimes, time.Time.Equal) {
+		t.Errorf("ModTimes() = %v, %v, want %v", got, err, want.modTimes)
+	}
+	if got, err := h.IsDirty(a); err != nil || !got {
+		t.Errorf("IsDirty() = %v, %v, want true", got, err)
+	}
+}
+
+func TestFSHistory(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "a.go")
+	if err := os.WriteFile(filename, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	modTime := yearTime(2022)
+	if err := os.Chtimes(filename, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	h := FSHistory()
+	if got, err := h.Created(filename); err != nil || !got.Equal(modTime) {
+		t.Errorf("Created() = %v, %v, want %v", got, err, modTime)
+	}
+	if got, err := h.Modified(filename); err != nil || !got.Equal(modTime) {
+		t.Errorf("Modified() = %v, %v, want %v", got, err, modTime)
+	}
+	if got, err := h.ModTimes(filename); err != nil || !slices.EqualFunc(got, []time.Time{modTime}, time.Time.Equal) {
+		t.Errorf("ModTimes() = %v, %v, want [%v]", got, err, modTime)
+	}
+	if _, err := h.Modified(filepath.Join(t.TempDir(), "missing.go")); err == nil {
+		t.Errorf("Modified(missing.go) err = nil, want error")
+	}
+}
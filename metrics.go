@@ -0,0 +1,151 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golicenser
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics are process-wide golicenser metrics. They are shared between all
+// analyzers and linters in the process.
+type Metrics struct {
+	// GitCalls is the number of git processes run.
+	GitCalls int64
+
+	// GitTime is the total time spent running git processes.
+	GitTime time.Duration
+
+	// GitWaitTime is the total time spent waiting for the process limiter
+	// before running git processes.
+	GitWaitTime time.Duration
+
+	// HistoryLookups is the number of file history lookups by year modes.
+	HistoryLookups int64
+
+	// HistoryHits is the number of file history lookups answered by the
+	// shared history memo.
+	HistoryHits int64
+
+	// CacheHits is the number of files skipped using the on-disk result
+	// cache.
+	CacheHits int64
+
+	// CacheMisses is the number of files checked that were not in the
+	// on-disk result cache.
+	CacheMisses int64
+}
+
+// metrics are the process-wide metrics.
+var metrics struct {
+	gitCalls       atomic.Int64
+	gitTime        atomic.Int64
+	gitWaitTime    atomic.Int64
+	historyLookups atomic.Int64
+	historyHits    atomic.Int64
+	cacheHits      atomic.Int64
+	cacheMisses    atomic.Int64
+}
+
+// ReadMetrics returns the current process-wide metrics. This is the only way to
+// read the metrics of an analyzer, as the analysis driver exits without
+// returning control after running the analyzers.
+func ReadMetrics() Metrics {
+	return Metrics{
+		GitCalls:       metrics.gitCalls.Load(),
+		GitTime:        time.Duration(metrics.gitTime.Load()),
+		GitWaitTime:    time.Duration(metrics.gitWaitTime.Load()),
+		HistoryLookups: metrics.historyLookups.Load(),
+		HistoryHits:    metrics.historyHits.Load(),
+		CacheHits:      metrics.cacheHits.Load(),
+		CacheMisses:    metrics.cacheMisses.Load(),
+	}
+}
+
+// processLimiter limits the number of concurrent external processes. The
+// analysis driver runs many passes concurrently, each checking files
+// concurrently, so without a process-wide limit the number of git processes
+// could grow to the number of packages multiplied by Config.MaxConcurrent.
+type processLimiter struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	limit  int
+	active int
+}
+
+// processes limits the external processes started by golicenser.
+var processes = newProcessLimiter(runtime.GOMAXPROCS(0))
+
+// newProcessLimiter creates a process limiter allowing n concurrent processes.
+func newProcessLimiter(n int) *processLimiter {
+	l := &processLimiter{limit: max(n, 1)}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// setLimit sets the number of concurrent processes.
+func (l *processLimiter) setLimit(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = max(n, 1)
+	l.cond.Broadcast()
+}
+
+// acquire waits until a process can be started.
+func (l *processLimiter) acquire() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.active >= l.limit {
+		l.cond.Wait()
+	}
+	l.active++
+}
+
+// release releases a process acquired using acquire.
+func (l *processLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	l.cond.Signal()
+}
+
+// startProcess waits for the process limiter, returning a function that must
+// be called once the process has exited. The git metrics are recorded.
+func startProcess() (done func()) {
+	waitStart := time.Now()
+	processes.acquire()
+	start := time.Now()
+	metrics.gitWaitTime.Add(int64(start.Sub(waitStart)))
+	return func() {
+		metrics.gitCalls.Add(1)
+		metrics.gitTime.Add(int64(time.Since(start)))
+		processes.release()
+	}
+}
+
+// gitOutput runs git with the arguments, returning its standard output.
+func gitOutput(args ...string) ([]byte, error) {
+	done := startProcess()
+	defer done()
+	return execCommand("git", args...).Output()
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golicenser
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProcessLimiter(t *testing.T) {
+	t.Parallel()
+
+	l := newProcessLimiter(2)
+	var active, peak atomic.Int64
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.acquire()
+			defer l.release()
+			n := active.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			active.Add(-1)
+		}()
+	}
+	wg.Wait()
+	if got := peak.Load(); got > 2 {
+		t.Errorf("peak concurrent processes = %d, want at most 2", got)
+	}
+
+	// Raising the limit wakes waiting processes.
+	l.setLimit(1)
+	l.acquire()
+	acquired := make(chan struct{})
+	go func() {
+		l.acquire()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatalf("acquire() exceeded limit")
+	case <-time.After(10 * time.Millisecond):
+	}
+	l.setLimit(2)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatalf("acquire() not woken after raising limit")
+	}
+}
+
+// countingHistory is a History counting the calls for each file.
+type countingHistory struct {
+	testHistory
+	calls atomic.Int64
+}
+
+func (h *countingHistory) Modified(filename string) (time.Time, error) {
+	h.calls.Add(1)
+	return h.testHistory.Modified(filename)
+}
+
+func TestMemoHistory(t *testing.T) {
+	t.Parallel()
+
+	h := &countingHistory{testHistory: testHistory{modified: yearTime(2024)}}
+	m := newMemoHistory(h)
+	if newMemoHistory(m) != m {
+		t.Errorf("newMemoHistory() of memo history is not the same memo")
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, name := range []string{"a.go", "b.go"} {
+				got, err := m.Modified(name)
+				if err != nil || got.Year() != 2024 {
+					t.Errorf("Modified(%q) = %v, %v, want 2024", name, got, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if got := h.calls.Load(); got != 2 {
+		t.Errorf("history calls = %d, want 2 (one per file)", got)
+	}
+
+	// Modifying the returned mod times does not affect the memoized result.
+	h.modTimes = []time.Time{yearTime(2020), yearTime(2022)}
+	modTimes, err := m.ModTimes("c.go")
+	if err != nil {
+		t.Fatalf("ModTimes() err = %v", err)
+	}
+	modTimes[0] = yearTime(1999)
+	if modTimes, _ = m.ModTimes("c.go"); modTimes[0].Year() != 2020 {
+		t.Errorf("ModTimes() after modifying result = %v, want first year 2020", modTimes)
+	}
+
+	if _, err := m.historyState("a.go"); err == nil {
+		t.Errorf("historyState() of unknown history err = nil, want error")
+	}
+}
+
+func TestReadMetrics(t *testing.T) {
+	t.Parallel()
+
+	before := ReadMetrics()
+	m := newMemoHistory(testHistory{})
+	_, _ = m.IsDirty("a.go")
+	_, _ = m.IsDirty("a.go")
+	after := ReadMetrics()
+	if got := after.HistoryLookups - before.HistoryLookups; got < 2 {
+		t.Errorf("HistoryLookups increased by %d, want at least 2", got)
+	}
+	if got := after.HistoryHits - before.HistoryHits; got < 1 {
+		t.Errorf("HistoryHits increased by %d, want at least 1", got)
+	}
+}
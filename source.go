@@ -0,0 +1,423 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golicenser
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// cFamilyExts are the extensions of files using C-style comments. These files
+// use the configured Go comment style.
+var cFamilyExts = map[string]bool{
+	".c": true, ".h": true, ".cc": true, ".cpp": true, ".cxx": true,
+	".hh": true, ".hpp": true, ".hxx": true, ".m": true, ".s": true,
+	".S": true, ".proto": true, ".java": true, ".kt": true, ".scala": true,
+	".groovy": true, ".swift": true, ".rs": true, ".cs": true, ".dart": true,
+	".js": true, ".mjs": true, ".cjs": true, ".jsx": true, ".ts": true,
+	".tsx": true, ".scss": true, ".less": true,
+}
+
+// extCommentStyles are the comment styles used for file extensions.
+var extCommentStyles = map[string]CommentStyle{
+	".css":        CommentStyleBlock,
+	".sh":         CommentStyleHash,
+	".bash":       CommentStyleHash,
+	".zsh":        CommentStyleHash,
+	".fish":       CommentStyleHash,
+	".py":         CommentStyleHash,
+	".rb":         CommentStyleHash,
+	".pl":         CommentStyleHash,
+	".pm":         CommentStyleHash,
+	".r":          CommentStyleHash,
+	".ps1":        CommentStyleHash,
+	".yaml":       CommentStyleHash,
+	".yml":        CommentStyleHash,
+	".toml":       CommentStyleHash,
+	".tf":         CommentStyleHash,
+	".tfvars":     CommentStyleHash,
+	".hcl":        CommentStyleHash,
+	".nix":        CommentStyleHash,
+	".mk":         CommentStyleHash,
+	".cmake":      CommentStyleHash,
+	".bzl":        CommentStyleHash,
+	".bazel":      CommentStyleHash,
+	".dockerfile": CommentStyleHash,
+	".sql":        CommentStyleDoubleDash,
+	".lua":        CommentStyleDoubleDash,
+	".hs":         CommentStyleDoubleDash,
+	".html":       CommentStyleXML,
+	".htm":        CommentStyleXML,
+	".xml":        CommentStyleXML,
+	".xsd":        CommentStyleXML,
+	".xsl":        CommentStyleXML,
+	".svg":        CommentStyleXML,
+}
+
+// nameCommentStyles are the comment styles used for file names.
+var nameCommentStyles = map[string]CommentStyle{
+	"Makefile":       CommentStyleHash,
+	"GNUmakefile":    CommentStyleHash,
+	"makefile":       CommentStyleHash,
+	"Dockerfile":     CommentStyleHash,
+	"Containerfile":  CommentStyleHash,
+	"CMakeLists.txt": CommentStyleHash,
+	"BUILD":          CommentStyleHash,
+	"WORKSPACE":      CommentStyleHash,
+	"Gemfile":        CommentStyleHash,
+	"Rakefile":       CommentStyleHash,
+	"Vagrantfile":    CommentStyleHash,
+}
+
+// commentStyleForFile returns the comment style to use for a non-Go file.
+// Files using C-style comments use goStyle. If the file type is not supported,
+// false is returned.
+func commentStyleForFile(filename string, goStyle CommentStyle) (CommentStyle, bool) {
+	base := filepath.Base(filename)
+	if cs, ok := nameCommentStyles[base]; ok {
+		return cs, true
+	}
+	if strings.HasPrefix(base, "Dockerfile.") || strings.HasPrefix(base, "Containerfile.") {
+		return CommentStyleHash, true
+	}
+
+	ext := filepath.Ext(base)
+	if cFamilyExts[ext] {
+		return goStyle, true
+	}
+	if cs, ok := extCommentStyles[strings.ToLower(ext)]; ok {
+		return cs, true
+	}
+	return 0, false
+}
+
+// sourceHeader is the location of the license header in a non-Go file.
+type sourceHeader struct {
+	// start is the offset after any lines that must remain at the start of
+	// the file, such as shebang lines and XML prologs. New license headers are
+	// inserted at this offset.
+	start int
+
+	// prefix must be inserted before new license headers. This is used when
+	// the file does not end with a newline after the start lines.
+	prefix string
+
+	// header is the leading comment of the file, if any.
+	header                 string
+	headerStart, headerEnd int
+}
+
+// findSourceHeader finds the license header in a non-Go source file.
+func findSourceHeader(src []byte, style CommentStyle) sourceHeader {
+	var sh sourceHeader
+
+	// Skip lines that must remain at the start of the file.
+	for sh.start < len(src) {
+		rest := src[sh.start:]
+		var n int
+		switch {
+		case sh.start == 0 && bytes.HasPrefix(rest, []byte("#!")):
+			n = lineLen(rest)
+		case style == CommentStyleXML && bytes.HasPrefix(rest, []byte("<?xml")):
+			n = declLen(rest, "?>")
+		case style == CommentStyleXML && hasPrefixFold(rest, "<!DOCTYPE"):
+			n = declLen(rest, ">")
+		}
+		if n == 0 {
+			break
+		}
+		sh.start += n
+	}
+	if sh.start > 0 && src[sh.start-1] != '\n' {
+		sh.prefix = "\n"
+	}
+
+	// Skip blank lines before the leading comment.
+	pos := sh.start
+	for pos < len(src) {
+		n := lineLen(src[pos:])
+		if len(bytes.TrimSpace(src[pos:pos+n])) != 0 {
+			break
+		}
+		pos += n
+	}
+
+	// Find the leading comment.
+	end := pos
+	if prefixes := commentPrefixes(style); len(prefixes) > 0 {
+		for end < len(src) && hasAnyPrefix(src[end:], prefixes) {
+			end += lineLen(src[end:])
+		}
+	}
+	if end == pos {
+		for _, delims := range commentDelimiters(style) {
+			if !bytes.HasPrefix(src[pos:], []byte(delims[0])) {
+				continue
+			}
+			if i := bytes.Index(src[pos:], []byte(delims[1])); i != -1 {
+				end = pos + i + len(delims[1])
+				end += lineLen(src[end:])
+			}
+			break
+		}
+	}
+	if end > pos {
+		sh.headerStart, sh.headerEnd = pos, end
+		sh.header = string(src[pos:end])
+		if !strings.HasSuffix(sh.header, "\n") {
+			sh.header += "\n"
+		}
+	}
+
+	return sh
+}
+
+// commentPrefixes returns the line comment prefixes that may be used by files
+// using the comment style.
+func commentPrefixes(style CommentStyle) []string {
+	if style.isGo() {
+		return []string{linePrefixes[CommentStyleLine]}
+	}
+	if prefix, ok := linePrefixes[style]; ok {
+		return []string{prefix}
+	}
+	return nil
+}
+
+// commentDelimiters returns the block comment delimiters that may be used by
+// files using the comment style.
+func commentDelimiters(style CommentStyle) [][2]string {
+	if style.isGo() {
+		return [][2]string{blockDelimiters[CommentStyleBlock]}
+	}
+	if delims, ok := blockDelimiters[style]; ok {
+		return [][2]string{delims}
+	}
+	return nil
+}
+
+// lineLen returns the length of the first line in b, including the newline.
+func lineLen(b []byte) int {
+	if i := bytes.IndexByte(b, '\n'); i != -1 {
+		return i + 1
+	}
+	return len(b)
+}
+
+// declLen returns the length of a declaration ending with end. If only
+// whitespace follows the declaration on the line it ends on, the rest of the
+// line is included.
+func declLen(b []byte, end string) int {
+	i := bytes.Index(b, []byte(end))
+	if i == -1 {
+		return 0
+	}
+	i += len(end)
+	if n := lineLen(b[i:]); len(bytes.TrimSpace(b[i:i+n])) == 0 {
+		return i + n
+	}
+	return i
+}
+
+func hasAnyPrefix(b []byte, prefixes []string) bool {
+	for _, p := range prefixes {
+		if bytes.HasPrefix(b, []byte(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPrefixFold(b []byte, prefix string) bool {
+	return len(b) >= len(prefix) && strings.EqualFold(string(b[:len(prefix)]), prefix)
+}
+
+// checkSource checks the license header of a non-Go source file.
+func (a *analyzer) checkSource(report func(analysis.Diagnostic), tf *token.File, src []byte, style CommentStyle) error {
+	filename := tf.Name()
+	sh := findSourceHeader(src, style)
+	return a.checkHeader(report, headerLocation{
+		filename: filename,
+		header:   sh.header,
+		pos:      tf.Pos(sh.headerStart),
+		end:      tf.Pos(sh.headerEnd),
+		insert:   tf.Pos(sh.start),
+		prefix:   sh.prefix,
+		style:    style,
+	})
+}
+
+// Linter checks license headers of files outside of the go/analysis
+// framework, such as shell scripts, Dockerfiles and YAML files.
+type Linter struct {
+	a *analyzer
+
+	// cache is the result cache, or nil if results are not cached.
+	cache *resultCache
+}
+
+// NewLinter creates a new Linter.
+func NewLinter(cfg Config) (*Linter, error) {
+	a, err := newAnalyzer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	l := &Linter{a: a}
+	if cfg.CacheDir != "" {
+		if l.cache, err = newResultCache(cfg.CacheDir, cfg); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Issue is a license header issue found by a Linter.
+type Issue struct {
+	Pos      token.Position
+	Category string
+	Message  string
+
+	// Fixed is the content of the file with the suggested fix applied, or nil
+	// if there is no suggested fix.
+	Fixed []byte
+}
+
+// String returns a string representation of the issue.
+func (i Issue) String() string {
+	return i.Pos.String() + ": " + i.Message
+}
+
+// Check checks the license header of a file. For non-Go files, the comment
+// style is selected using the file name. Excluded files, generated Go files
+// and files with an unsupported file type are skipped.
+//
+// If Config.CacheDir is set, results are cached, and unchanged files are
+// skipped.
+func (l *Linter) Check(filename string, src []byte) ([]Issue, error) {
+	if l.a.excluded(filename) {
+		return nil, nil
+	}
+
+	key, cacheable := l.cacheKey(filename, src)
+	if cacheable {
+		if issues, ok := l.cache.get(key); ok {
+			metrics.cacheHits.Add(1)
+			return issues, nil
+		}
+		metrics.cacheMisses.Add(1)
+	}
+	issues, err := l.check(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	if cacheable {
+		// Failing to cache the result does not affect the result.
+		_ = l.cache.put(key, issues)
+	}
+	return issues, nil
+}
+
+// check checks the license header of a file.
+func (l *Linter) check(filename string, src []byte) ([]Issue, error) {
+	var diags []analysis.Diagnostic
+	report := func(d analysis.Diagnostic) {
+		diags = append(diags, d)
+	}
+
+	fset := token.NewFileSet()
+	var tf *token.File
+	isGo := filepath.Ext(filename) == ".go"
+	if isGo {
+		file, err := parser.ParseFile(fset, filename, src, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", filename, err)
+		}
+		if ast.IsGenerated(file) {
+			return nil, nil
+		}
+		tf = fset.File(file.FileStart)
+		if err = l.a.checkFile(fset, report, file); err != nil {
+			return nil, fmt.Errorf("check %s: %w", filename, err)
+		}
+	} else {
+		style, ok := commentStyleForFile(filename, l.a.headerFor(filename).commentStyle)
+		if !ok {
+			return nil, nil
+		}
+		tf = fset.AddFile(filename, -1, len(src))
+		tf.SetLinesForContent(src)
+		if err := l.a.checkSource(report, tf, src, style); err != nil {
+			return nil, fmt.Errorf("check %s: %w", filename, err)
+		}
+	}
+
+	issues := make([]Issue, 0, len(diags))
+	for _, d := range diags {
+		issue := Issue{
+			Pos:      fset.Position(d.Pos),
+			Category: d.Category,
+			Message:  d.Message,
+		}
+		if len(d.SuggestedFixes) > 0 {
+			issue.Fixed = applyEdits(tf, src, d.SuggestedFixes[0].TextEdits)
+			if isGo {
+				// Format fixed Go files, as done by the analysis driver.
+				if formatted, err := format.Source(issue.Fixed); err == nil {
+					issue.Fixed = formatted
+				}
+			}
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// applyEdits applies the text edits to src.
+func applyEdits(tf *token.File, src []byte, edits []analysis.TextEdit) []byte {
+	edits = slices.Clone(edits)
+	slices.SortStableFunc(edits, func(a, b analysis.TextEdit) int {
+		return int(a.Pos - b.Pos)
+	})
+
+	var b bytes.Buffer
+	var last int
+	for _, edit := range edits {
+		start := tf.Offset(edit.Pos)
+		end := start
+		if edit.End.IsValid() {
+			end = tf.Offset(edit.End)
+		}
+		b.Write(src[last:start])
+		b.Write(edit.NewText)
+		last = end
+	}
+	b.Write(src[last:])
+	return b.Bytes()
+}
@@ -0,0 +1,298 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golicenser
+
+import (
+	"testing"
+)
+
+func TestCommentStyleForFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		filename string
+		goStyle  CommentStyle
+		want     CommentStyle
+		wantOK   bool
+	}{
+		{filename: "/repo/script.sh", want: CommentStyleHash, wantOK: true},
+		{filename: "/repo/Makefile", want: CommentStyleHash, wantOK: true},
+		{filename: "/repo/Dockerfile", want: CommentStyleHash, wantOK: true},
+		{filename: "/repo/Dockerfile.dev", want: CommentStyleHash, wantOK: true},
+		{filename: "/repo/.goreleaser.Dockerfile", want: CommentStyleHash, wantOK: true},
+		{filename: "/repo/.github/workflows/go.yml", want: CommentStyleHash, wantOK: true},
+		{filename: "/repo/migrations/001_init.SQL", want: CommentStyleDoubleDash, wantOK: true},
+		{filename: "/repo/pom.xml", want: CommentStyleXML, wantOK: true},
+		{filename: "/repo/style.css", want: CommentStyleBlock, wantOK: true},
+		{filename: "/repo/api.proto", want: CommentStyleLine, wantOK: true},
+		{filename: "/repo/api.proto", goStyle: CommentStyleBlock, want: CommentStyleBlock, wantOK: true},
+		{filename: "/repo/README.md", wantOK: false},
+		{filename: "/repo/LICENSE", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := commentStyleForFile(tt.filename, tt.goStyle)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("commentStyleForFile(%q, %v) = %v, %v, want %v, %v",
+				tt.filename, tt.goStyle, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestFindSourceHeader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		src        string
+		style      CommentStyle
+		wantStart  int
+		wantPrefix string
+		wantHeader string
+	}{
+		{
+			name:  "empty",
+			src:   "",
+			style: CommentStyleHash,
+		},
+		{
+			name:  "no comment",
+			src:   "FROM scratch\n",
+			style: CommentStyleHash,
+		},
+		{
+			name:       "hash comment",
+			src:        "# Copyright (c) 2025 Test\n#\n# Line 2\n\nFROM scratch\n",
+			style:      CommentStyleHash,
+			wantHeader: "# Copyright (c) 2025 Test\n#\n# Line 2\n",
+		},
+		{
+			name:       "shebang",
+			src:        "#!/bin/sh\n# Copyright (c) 2025 Test\n\necho hello\n",
+			style:      CommentStyleHash,
+			wantStart:  len("#!/bin/sh\n"),
+			wantHeader: "# Copyright (c) 2025 Test\n",
+		},
+		{
+			name:       "shebang without newline",
+			src:        "#!/bin/sh",
+			style:      CommentStyleHash,
+			wantStart:  len("#!/bin/sh"),
+			wantPrefix: "\n",
+		},
+		{
+			name:       "xml prolog",
+			src:        "<?xml version=\"1.0\"?>\n<!--\nCopyright (c) 2025 Test\n-->\n<project/>\n",
+			style:      CommentStyleXML,
+			wantStart:  len("<?xml version=\"1.0\"?>\n"),
+			wantHeader: "<!--\nCopyright (c) 2025 Test\n-->\n",
+		},
+		{
+			name:       "xml prolog and root element on one line",
+			src:        "<?xml version=\"1.0\"?><a/>\n",
+			style:      CommentStyleXML,
+			wantStart:  len("<?xml version=\"1.0\"?>"),
+			wantPrefix: "\n",
+		},
+		{
+			name:      "xml prolog with trailing whitespace",
+			src:       "<?xml version=\"1.0\"?>  \n<a/>\n",
+			style:     CommentStyleXML,
+			wantStart: len("<?xml version=\"1.0\"?>  \n"),
+		},
+		{
+			name:      "doctype",
+			src:       "<!DOCTYPE html>\n<html></html>\n",
+			style:     CommentStyleXML,
+			wantStart: len("<!DOCTYPE html>\n"),
+		},
+		{
+			name:       "double dash",
+			src:        "-- Copyright (c) 2025 Test\nCREATE TABLE t ();\n",
+			style:      CommentStyleDoubleDash,
+			wantHeader: "-- Copyright (c) 2025 Test\n",
+		},
+		{
+			name:       "c block comment",
+			src:        "/*\nCopyright (c) 2025 Test\n*/\n#include <stdio.h>\n",
+			style:      CommentStyleLine,
+			wantHeader: "/*\nCopyright (c) 2025 Test\n*/\n",
+		},
+		{
+			name:       "c line comment without trailing newline",
+			src:        "// Copyright (c) 2025 Test",
+			style:      CommentStyleBlock,
+			wantHeader: "// Copyright (c) 2025 Test\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sh := findSourceHeader([]byte(tt.src), tt.style)
+			if sh.start != tt.wantStart {
+				t.Errorf("start = %d, want %d", sh.start, tt.wantStart)
+			}
+			if sh.prefix != tt.wantPrefix {
+				t.Errorf("prefix = %q, want %q", sh.prefix, tt.wantPrefix)
+			}
+			if sh.header != tt.wantHeader {
+				t.Errorf("header = %q, want %q", sh.header, tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestLinterCheck(t *testing.T) {
+	t.Parallel()
+
+	l, err := NewLinter(Config{
+		Header: HeaderOpts{
+			Template: "Copyright (c) {{.year}} {{.author}}",
+			Author:   "Test",
+			YearMode: YearModeThisYear,
+			Migrations: []Migration{{
+				From: "Copyright (c) {{.year}} {{.author}}\nUse of this source code is governed by an MIT license.",
+			}},
+		},
+		Exclude: []string{"**/excluded/**"},
+	})
+	if err != nil {
+		t.Fatalf("NewLinter() err = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		filename     string
+		src          string
+		wantMessage  string
+		wantCategory string
+		wantFixed    string
+	}{
+		{
+			name:        "missing",
+			filename:    "/repo/Dockerfile",
+			src:         "FROM scratch\n",
+			wantMessage: "missing license header",
+			wantFixed:   "# Copyright (c) 2025 Test\n\nFROM scratch\n",
+		},
+		{
+			name:        "missing with shebang",
+			filename:    "/repo/run.sh",
+			src:         "#!/bin/sh\necho hello\n",
+			wantMessage: "missing license header",
+			wantFixed:   "#!/bin/sh\n# Copyright (c) 2025 Test\n\necho hello\n",
+		},
+		{
+			name:        "missing with xml prolog",
+			filename:    "/repo/pom.xml",
+			src:         "<?xml version=\"1.0\"?>\n<project/>\n",
+			wantMessage: "missing license header",
+			wantFixed:   "<?xml version=\"1.0\"?>\n<!--\nCopyright (c) 2025 Test\n-->\n\n<project/>\n",
+		},
+		{
+			name:        "missing with xml prolog and root element on one line",
+			filename:    "/repo/pom.xml",
+			src:         "<?xml version=\"1.0\"?><project/>\n",
+			wantMessage: "missing license header",
+			wantFixed:   "<?xml version=\"1.0\"?>\n<!--\nCopyright (c) 2025 Test\n-->\n\n<project/>\n",
+		},
+		{
+			name:        "outdated",
+			filename:    "/repo/migrations/001.sql",
+			src:         "-- Copyright (c) 2001 Test\n\nCREATE TABLE t ();\n",
+			wantMessage: "invalid license header",
+			wantFixed:   "-- Copyright (c) 2025 Test\n\nCREATE TABLE t ();\n",
+		},
+		{
+			name:     "valid",
+			filename: "/repo/config.yaml",
+			src:      "# Copyright (c) 2025 Test\n\nkey: value\n",
+		},
+		{
+			name:     "different header",
+			filename: "/repo/config.yaml",
+			src:      "# Copyright (c) 2018 Someone else\n\nkey: value\n",
+		},
+		{
+			name:        "go missing",
+			filename:    "/repo/main.go",
+			src:         "//go:build linux\n\npackage main\n",
+			wantMessage: "missing license header",
+			wantFixed:   "// Copyright (c) 2025 Test\n\n//go:build linux\n\npackage main\n",
+		},
+		{
+			name:        "go outdated",
+			filename:    "/repo/main.go",
+			src:         "// Copyright (c) 2001 Test\n\npackage main\n",
+			wantMessage: "invalid license header",
+			wantFixed:   "// Copyright (c) 2025 Test\n\npackage main\n",
+		},
+		{
+			name:         "go migration",
+			filename:     "/repo/main.go",
+			src:          "// Copyright (c) 2019 Test\n// Use of this source code is governed by an MIT license.\n\npackage main\n",
+			wantMessage:  "license header requires migration",
+			wantCategory: CategoryMigrate,
+			wantFixed:    "// Copyright (c) 2025 Test\n\npackage main\n",
+		},
+		{
+			name:     "go generated",
+			filename: "/repo/gen.go",
+			src:      "// Code generated by test. DO NOT EDIT.\n\npackage main\n",
+		},
+		{
+			name:     "excluded",
+			filename: "/repo/excluded/run.sh",
+			src:      "echo hello\n",
+		},
+		{
+			name:     "unsupported",
+			filename: "/repo/README.md",
+			src:      "# golicenser\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			issues, err := l.Check(tt.filename, []byte(tt.src))
+			if err != nil {
+				t.Fatalf("Check() err = %v", err)
+			}
+			if tt.wantMessage == "" {
+				if len(issues) != 0 {
+					t.Errorf("Check() = %v, want no issues", issues)
+				}
+				return
+			}
+			if len(issues) != 1 {
+				t.Fatalf("Check() = %v, want 1 issue", issues)
+			}
+			if issues[0].Message != tt.wantMessage {
+				t.Errorf("Check() message = %q, want %q", issues[0].Message, tt.wantMessage)
+			}
+			if issues[0].Category != tt.wantCategory && tt.wantCategory != "" {
+				t.Errorf("Check() category = %q, want %q", issues[0].Category, tt.wantCategory)
+			}
+			if got := string(issues[0].Fixed); got != tt.wantFixed {
+				t.Errorf("Check() fixed = %q, want %q", got, tt.wantFixed)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golicenser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// StagedFile is a file staged for commit.
+type StagedFile struct {
+	// Path is the absolute path of the file.
+	Path string
+
+	// Src is the staged content of the file.
+	Src []byte
+}
+
+// ReadStagedFiles returns the files staged for commit in the Git repository
+// containing dir, with their staged content, for checking using
+// Linter.Check. Deleted files, submodules and symbolic links are skipped.
+func ReadStagedFiles(dir string) ([]StagedFile, error) {
+	out, err := gitOutput("-C", dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("find git repository: %w", err)
+	}
+	root := filepath.FromSlash(strings.TrimSpace(string(out)))
+
+	out, err = gitOutput("-C", root, "-c", "core.quotePath=false",
+		"diff", "--cached", "--raw", "-z", "--no-renames", "--no-abbrev", "--diff-filter=d")
+	if err != nil {
+		return nil, fmt.Errorf("git diff --cached: %w", err)
+	}
+	names, err := parseDiffRaw(out)
+	if err != nil {
+		return nil, fmt.Errorf("parse git diff --cached: %w", err)
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	// Read the staged blobs using a single git process.
+	var stdin bytes.Buffer
+	for _, name := range names {
+		stdin.WriteString(":" + name + "\n")
+	}
+	cmd := execCommand("git", "-C", root, "cat-file", "--batch")
+	cmd.Stdin = &stdin
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	done := startProcess()
+	if err = cmd.Start(); err != nil {
+		done()
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	files, parseErr := parseCatFileBatch(stdout, root, names)
+	if parseErr != nil {
+		// Drain the output so that git can exit.
+		_, _ = io.Copy(io.Discard, stdout)
+	}
+	err = cmd.Wait()
+	done()
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	if parseErr != nil {
+		return nil, fmt.Errorf("parse git cat-file: %w", parseErr)
+	}
+	return files, nil
+}
+
+// Git file modes of index entries that are not regular files.
+const (
+	gitModeSymlink = "120000"
+	gitModeGitlink = "160000"
+)
+
+// parseDiffRaw parses the output of 'git diff --raw -z --no-renames',
+// returning the paths of the changed files. Symbolic links and submodules
+// (gitlinks) are skipped using their mode in the index, as the working tree
+// may differ from what is staged.
+func parseDiffRaw(out []byte) ([]string, error) {
+	var names []string
+	fields := bytes.Split(bytes.TrimSuffix(out, []byte{0}), []byte{0})
+	for i := 0; i < len(fields); i += 2 {
+		if len(fields[i]) == 0 {
+			continue
+		}
+		// Each entry is ":<old mode> <new mode> <old hash> <new hash> <status>"
+		// followed by the path.
+		meta := strings.Fields(string(fields[i]))
+		if len(meta) != 5 || !strings.HasPrefix(meta[0], ":") || i+1 >= len(fields) {
+			return nil, fmt.Errorf("invalid entry %q", fields[i])
+		}
+		if mode := meta[1]; mode == gitModeSymlink || mode == gitModeGitlink {
+			continue
+		}
+		names = append(names, string(fields[i+1]))
+	}
+	return names, nil
+}
+
+// parseCatFileBatch parses the output of 'git cat-file --batch' for the staged
+// files, which are relative to the repository root. Objects that are not blobs
+// are skipped.
+func parseCatFileBatch(r io.Reader, root string, names []string) ([]StagedFile, error) {
+	br := bufio.NewReader(r)
+	files := make([]StagedFile, 0, len(names))
+	for _, name := range names {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("read object header for %s: %w", name, err)
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == "missing" {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid object header for %s: %q", name, line)
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid object size for %s: %q", name, line)
+		}
+
+		// Content is followed by a newline.
+		content := make([]byte, size+1)
+		if _, err = io.ReadFull(br, content); err != nil {
+			return nil, fmt.Errorf("read object %s: %w", name, err)
+		}
+		if fields[1] != "blob" {
+			continue
+		}
+		path := filepath.Join(root, filepath.FromSlash(name))
+		files = append(files, StagedFile{Path: path, Src: content[:size]})
+	}
+	return files, nil
+}
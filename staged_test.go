@@ -0,0 +1,146 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golicenser
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseCatFileBatch(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	out := "1111 blob 10\npackage a\n\n" +
+		"2222 commit 4\ntree\n" +
+		":c.go missing\n" +
+		"3333 blob 0\n\n"
+	got, err := parseCatFileBatch(strings.NewReader(out), root, []string{"a.go", "sub", "c.go", "d/e.txt"})
+	if err != nil {
+		t.Fatalf("parseCatFileBatch() err = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("parseCatFileBatch() = %+v, want 2 files", got)
+	}
+	if got[0].Path != filepath.Join(root, "a.go") || string(got[0].Src) != "package a\n" {
+		t.Errorf("parseCatFileBatch()[0] = %+v", got[0])
+	}
+	if got[1].Path != filepath.Join(root, "d", "e.txt") || len(got[1].Src) != 0 {
+		t.Errorf("parseCatFileBatch()[1] = %+v", got[1])
+	}
+
+	if _, err = parseCatFileBatch(strings.NewReader("1111 blob 100\nshort"), root, []string{"a.go"}); err == nil {
+		t.Errorf("parseCatFileBatch() with short content err = nil, want error")
+	}
+}
+
+func TestParseDiffRaw(t *testing.T) {
+	t.Parallel()
+
+	out := ":000000 100644 0000 1111 A\x00a.go\x00" +
+		":100644 100755 1111 2222 M\x00dir/run.sh\x00" +
+		":000000 120000 0000 3333 A\x00link.go\x00" +
+		":000000 160000 0000 4444 A\x00sub\x00" +
+		":120000 100644 3333 5555 T\x00was-link.go\x00"
+	got, err := parseDiffRaw([]byte(out))
+	if err != nil {
+		t.Fatalf("parseDiffRaw() err = %v", err)
+	}
+	if want := []string{"a.go", "dir/run.sh", "was-link.go"}; !slices.Equal(got, want) {
+		t.Errorf("parseDiffRaw() = %v, want %v", got, want)
+	}
+
+	if _, err = parseDiffRaw([]byte(":100644 100644 1111 2222 M\x00")); err == nil {
+		t.Errorf("parseDiffRaw() with missing path err = nil, want error")
+	}
+}
+
+func TestReadStagedFiles(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		runGit(t, dir, "", args...)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		writeFile(t, filepath.Join(dir, name), content)
+	}
+
+	write("a.go", "package a\n")
+	write("b.go", "package a\n")
+	write("c.sh", "echo c\n")
+	git("init", "-q")
+	git("add", "a.go", "c.sh")
+	git("commit", "-q", "-m", "initial commit")
+
+	// a.go is partially staged, b.go is staged and c.sh is deleted.
+	write("a.go", "// Copyright (c) 2025 Test\n\npackage a\n")
+	git("add", "a.go")
+	write("a.go", "package a\n")
+	git("add", "b.go")
+	git("rm", "-q", "c.sh")
+
+	// link.go is staged as a symbolic link, but is a regular file in the
+	// working tree, and sub is staged as a submodule.
+	if err := os.Symlink("a.go", filepath.Join(dir, "link.go")); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "link.go")
+	if err := os.Remove(filepath.Join(dir, "link.go")); err != nil {
+		t.Fatal(err)
+	}
+	write("link.go", "package a\n")
+	head, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	git("update-index", "--add", "--cacheinfo", "160000,"+strings.TrimSpace(string(head))+",sub")
+
+	files, err := ReadStagedFiles(dir)
+	if err != nil {
+		t.Fatalf("ReadStagedFiles() err = %v", err)
+	}
+	got := make(map[string]string)
+	for _, f := range files {
+		got[filepath.Base(f.Path)] = string(f.Src)
+	}
+	want := map[string]string{
+		"a.go": "// Copyright (c) 2025 Test\n\npackage a\n",
+		"b.go": "package a\n",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ReadStagedFiles() = %v, want %v", got, want)
+	}
+	for name, src := range want {
+		if got[name] != src {
+			t.Errorf("ReadStagedFiles()[%s] = %q, want %q", name, got[name], src)
+		}
+	}
+}
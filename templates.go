@@ -20,11 +20,32 @@
 
 package golicenser
 
+import (
+	_ "embed"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
 var licenseNameMap = map[string]string{
-	"Apache-2.0": LicenseApache2,
-	"MIT":        LicenseMIT,
-	"MIT-0":      LicenseMIT0,
-	"OpenBSD":    LicenseOpenBSD,
+	"0BSD":              License0BSD,
+	"AGPL-3.0-or-later": LicenseAGPL3OrLater,
+	"Apache-2.0":        LicenseApache2,
+	"BSD-2-Clause":      LicenseBSD2Clause,
+	"BSD-3-Clause":      LicenseBSD3Clause,
+	"EPL-2.0":           LicenseEPL2,
+	"GPL-2.0-or-later":  LicenseGPL2OrLater,
+	"GPL-3.0-or-later":  LicenseGPL3OrLater,
+	"ISC":               LicenseISC,
+	"LGPL-2.1-or-later": LicenseLGPL21OrLater,
+	"LGPL-3.0-or-later": LicenseLGPL3OrLater,
+	"MIT":               LicenseMIT,
+	"MIT-0":             LicenseMIT0,
+	"MPL-2.0":           LicenseMPL2,
+	"OpenBSD":           LicenseOpenBSD,
+	"Unlicense":         LicenseUnlicense,
 }
 
 // TemplateBySPDX returns a built-in template by its SPDX identifier.
@@ -33,6 +54,189 @@ func TemplateBySPDX(spdx string) (string, bool) {
 	return tmpl, ok
 }
 
+// Templates returns the SPDX identifiers of the built-in templates, sorted.
+func Templates() []string {
+	return slices.Sorted(maps.Keys(licenseNameMap))
+}
+
+// SPDXTemplate is the default template used for SPDX short-form license
+// headers, when an SPDX license expression is set without a template.
+const SPDXTemplate = `Copyright (c) {{.year}} {{.author}}
+SPDX-License-Identifier: {{.spdx}}`
+
+var (
+	//go:embed spdx/licenses.txt
+	spdxLicenseList string
+
+	//go:embed spdx/exceptions.txt
+	spdxExceptionList string
+)
+
+// spdxIDs returns the SPDX license and exception identifiers, keyed by their
+// lowercase form.
+var spdxIDs = sync.OnceValues(func() (licenses, exceptions map[string]string) {
+	return parseSPDXList(spdxLicenseList), parseSPDXList(spdxExceptionList)
+})
+
+func parseSPDXList(list string) map[string]string {
+	ids := make(map[string]string)
+	for _, id := range strings.Fields(list) {
+		ids[strings.ToLower(id)] = id
+	}
+	return ids
+}
+
+// ValidateSPDX validates an SPDX license expression, such as "Apache-2.0",
+// "MIT OR Apache-2.0" or "GPL-2.0-or-later WITH Classpath-exception-2.0".
+// License and exception identifiers must be on the SPDX license list, or be
+// a LicenseRef.
+func ValidateSPDX(expr string) error {
+	_, _, err := parseSPDX(expr)
+	return err
+}
+
+// parseSPDX parses an SPDX license expression. It returns the normalised
+// expression, with identifiers in their canonical case, and the license
+// identifiers used in the expression.
+func parseSPDX(expr string) (string, []string, error) {
+	p := &spdxParser{
+		tokens: strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expr)),
+	}
+	if len(p.tokens) == 0 {
+		return "", nil, fmt.Errorf("empty SPDX license expression")
+	}
+	s, err := p.compound()
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid SPDX license expression %q: %w", expr, err)
+	}
+	if tok := p.peek(); tok != "" {
+		return "", nil, fmt.Errorf("invalid SPDX license expression %q: unexpected %q", expr, tok)
+	}
+	return s, p.licenses, nil
+}
+
+// spdxParser is a recursive descent parser for SPDX license expressions.
+// https://spdx.github.io/spdx-spec/v2.3/SPDX-license-expressions/
+type spdxParser struct {
+	tokens   []string
+	licenses []string
+}
+
+func (p *spdxParser) peek() string {
+	if len(p.tokens) == 0 {
+		return ""
+	}
+	return p.tokens[0]
+}
+
+func (p *spdxParser) next() string {
+	tok := p.peek()
+	if tok != "" {
+		p.tokens = p.tokens[1:]
+	}
+	return tok
+}
+
+// operator consumes the next token if it is the given operator. Operators may
+// be all uppercase or all lowercase.
+func (p *spdxParser) operator(op string) bool {
+	if tok := p.peek(); tok == op || tok == strings.ToLower(op) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+// compound parses: and-expression { "OR" and-expression }
+func (p *spdxParser) compound() (string, error) {
+	return p.binary("OR", p.and)
+}
+
+// and parses: with-expression { "AND" with-expression }
+func (p *spdxParser) and() (string, error) {
+	return p.binary("AND", p.with)
+}
+
+func (p *spdxParser) binary(op string, operand func() (string, error)) (string, error) {
+	s, err := operand()
+	if err != nil {
+		return "", err
+	}
+	for p.operator(op) {
+		rhs, err := operand()
+		if err != nil {
+			return "", err
+		}
+		s += " " + op + " " + rhs
+	}
+	return s, nil
+}
+
+// with parses: "(" compound ")" | simple-expression [ "WITH" exception ]
+func (p *spdxParser) with() (string, error) {
+	if p.peek() == "(" {
+		p.next()
+		s, err := p.compound()
+		if err != nil {
+			return "", err
+		}
+		if p.next() != ")" {
+			return "", fmt.Errorf("missing closing parenthesis")
+		}
+		return "(" + s + ")", nil
+	}
+
+	s, err := p.simple()
+	if err != nil {
+		return "", err
+	}
+	if p.operator("WITH") {
+		tok := p.next()
+		_, exceptions := spdxIDs()
+		exception, ok := exceptions[strings.ToLower(tok)]
+		if !ok {
+			return "", fmt.Errorf("unknown license exception %q", tok)
+		}
+		s += " WITH " + exception
+	}
+	return s, nil
+}
+
+// simple parses: license-id [ "+" ] | [ "DocumentRef-" id ":" ] "LicenseRef-" id
+func (p *spdxParser) simple() (string, error) {
+	tok := p.next()
+	switch tok {
+	case "", "(", ")", "AND", "OR", "WITH", "and", "or", "with":
+		if tok == "" {
+			return "", fmt.Errorf("unexpected end of expression")
+		}
+		return "", fmt.Errorf("unexpected %q", tok)
+	}
+
+	ref := tok
+	if strings.HasPrefix(ref, "DocumentRef-") {
+		_, ref, _ = strings.Cut(ref, ":")
+	}
+	if strings.HasPrefix(ref, "LicenseRef-") {
+		if ref == "LicenseRef-" {
+			return "", fmt.Errorf("invalid license reference %q", tok)
+		}
+		return tok, nil
+	}
+
+	id, plus := strings.CutSuffix(tok, "+")
+	licenses, _ := spdxIDs()
+	license, ok := licenses[strings.ToLower(id)]
+	if !ok {
+		return "", fmt.Errorf("unknown license %q", id)
+	}
+	p.licenses = append(p.licenses, license)
+	if plus {
+		license += "+"
+	}
+	return license, nil
+}
+
 // LicenseApache2 is the Apache License, Version 2.0.
 // https://www.apache.org/licenses/LICENSE-2.0
 const LicenseApache2 = `Copyright {{.year}} {{.author}}
@@ -105,3 +309,224 @@ ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
 WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
 ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
 OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.`
+
+// License0BSD is the BSD Zero Clause License.
+// https://opensource.org/license/0bsd
+const License0BSD = `Copyright (c) {{.year}} {{.author}}
+
+Permission to use, copy, modify, and/or distribute this software for any
+purpose with or without fee is hereby granted.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR
+OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+PERFORMANCE OF THIS SOFTWARE.`
+
+// LicenseBSD2Clause is the BSD 2-Clause "Simplified" License.
+// https://opensource.org/license/bsd-2-clause
+const LicenseBSD2Clause = `Copyright (c) {{.year}} {{.author}}
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.`
+
+// LicenseBSD3Clause is the BSD 3-Clause "New" or "Revised" License.
+// https://opensource.org/license/bsd-3-clause
+const LicenseBSD3Clause = `Copyright (c) {{.year}} {{.author}}
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its
+   contributors may be used to endorse or promote products derived from
+   this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.`
+
+// LicenseISC is the ISC License.
+// https://opensource.org/license/isc-license-txt
+const LicenseISC = `Copyright (c) {{.year}} {{.author}}
+
+Permission to use, copy, modify, and/or distribute this software for any
+purpose with or without fee is hereby granted, provided that the above
+copyright notice and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.`
+
+// LicenseMPL2 is the Mozilla Public License 2.0 notice (Exhibit A).
+// https://www.mozilla.org/en-US/MPL/2.0/
+const LicenseMPL2 = `Copyright (c) {{.year}} {{.author}}
+
+This Source Code Form is subject to the terms of the Mozilla Public
+License, v. 2.0. If a copy of the MPL was not distributed with this
+file, You can obtain one at http://mozilla.org/MPL/2.0/.`
+
+// LicenseGPL2OrLater is the GNU General Public License v2.0 or later notice.
+// https://www.gnu.org/licenses/old-licenses/gpl-2.0.html
+const LicenseGPL2OrLater = `Copyright (C) {{.year}} {{.author}}
+
+This program is free software; you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation; either version 2 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License along
+with this program; if not, write to the Free Software Foundation, Inc.,
+51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.`
+
+// LicenseGPL3OrLater is the GNU General Public License v3.0 or later notice.
+// https://www.gnu.org/licenses/gpl-3.0.html
+const LicenseGPL3OrLater = `Copyright (C) {{.year}} {{.author}}
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.`
+
+// LicenseLGPL21OrLater is the GNU Lesser General Public License v2.1 or later
+// notice.
+// https://www.gnu.org/licenses/old-licenses/lgpl-2.1.html
+const LicenseLGPL21OrLater = `Copyright (C) {{.year}} {{.author}}
+
+This library is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 2.1 of the License, or (at your option) any later version.
+
+This library is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public
+License along with this library; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA`
+
+// LicenseLGPL3OrLater is the GNU Lesser General Public License v3.0 or later
+// notice.
+// https://www.gnu.org/licenses/lgpl-3.0.html
+const LicenseLGPL3OrLater = `Copyright (C) {{.year}} {{.author}}
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.`
+
+// LicenseAGPL3OrLater is the GNU Affero General Public License v3.0 or later
+// notice.
+// https://www.gnu.org/licenses/agpl-3.0.html
+const LicenseAGPL3OrLater = `Copyright (C) {{.year}} {{.author}}
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.`
+
+// LicenseEPL2 is the Eclipse Public License 2.0 notice (Exhibit A).
+// https://www.eclipse.org/legal/epl-2.0/
+const LicenseEPL2 = `Copyright (c) {{.year}} {{.author}}
+
+This program and the accompanying materials are made
+available under the terms of the Eclipse Public License 2.0
+which is available at https://www.eclipse.org/legal/epl-2.0/
+
+SPDX-License-Identifier: EPL-2.0`
+
+// LicenseUnlicense is the Unlicense. The Unlicense dedicates software to the
+// public domain, so the template does not contain a copyright line.
+// https://unlicense.org
+const LicenseUnlicense = `This is free and unencumbered software released into the public domain.
+
+Anyone is free to copy, modify, publish, use, compile, sell, or
+distribute this software, either in source code form or as a compiled
+binary, for any purpose, commercial or non-commercial, and by any
+means.
+
+In jurisdictions that recognize copyright laws, the author or authors
+of this software dedicate any and all copyright interest in the
+software to the public domain. We make this dedication for the benefit
+of the public at large and to the detriment of our heirs and
+successors. We intend this dedication to be an overt act of
+relinquishment in perpetuity of all present and future rights to this
+software under copyright law.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+IN NO EVENT SHALL THE AUTHORS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+OTHER DEALINGS IN THE SOFTWARE.
+
+For more information, please refer to <https://unlicense.org>`
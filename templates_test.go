@@ -0,0 +1,165 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golicenser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSPDX(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		expr         string
+		want         string
+		wantLicenses []string
+		wantErr      bool
+	}{
+		{
+			name:         "simple",
+			expr:         "Apache-2.0",
+			want:         "Apache-2.0",
+			wantLicenses: []string{"Apache-2.0"},
+		},
+		{
+			name:         "canonical case",
+			expr:         "mit",
+			want:         "MIT",
+			wantLicenses: []string{"MIT"},
+		},
+		{
+			name:         "or later",
+			expr:         "GPL-2.0+",
+			want:         "GPL-2.0+",
+			wantLicenses: []string{"GPL-2.0"},
+		},
+		{
+			name:         "compound",
+			expr:         "(MIT  OR apache-2.0) and BSD-3-Clause",
+			want:         "(MIT OR Apache-2.0) AND BSD-3-Clause",
+			wantLicenses: []string{"MIT", "Apache-2.0", "BSD-3-Clause"},
+		},
+		{
+			name:         "exception",
+			expr:         "GPL-2.0-or-later WITH classpath-exception-2.0",
+			want:         "GPL-2.0-or-later WITH Classpath-exception-2.0",
+			wantLicenses: []string{"GPL-2.0-or-later"},
+		},
+		{
+			name: "license ref",
+			expr: "LicenseRef-Proprietary OR DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2",
+			want: "LicenseRef-Proprietary OR DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2",
+		},
+		{
+			name:    "empty",
+			expr:    " ",
+			wantErr: true,
+		},
+		{
+			name:    "unknown license",
+			expr:    "MIT OR Foo-1.0",
+			wantErr: true,
+		},
+		{
+			name:    "unknown exception",
+			expr:    "MIT WITH Foo-exception",
+			wantErr: true,
+		},
+		{
+			name:    "mixed case operator",
+			expr:    "MIT Or Apache-2.0",
+			wantErr: true,
+		},
+		{
+			name:    "missing operand",
+			expr:    "MIT AND",
+			wantErr: true,
+		},
+		{
+			name:    "unbalanced parentheses",
+			expr:    "(MIT OR Apache-2.0",
+			wantErr: true,
+		},
+		{
+			name:    "empty license ref",
+			expr:    "LicenseRef-",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, licenses, err := parseSPDX(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseSPDX(%q) err = %v, want err %v", tt.expr, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSPDX(%q) = %q, want %q", tt.expr, got, tt.want)
+			}
+			if !slices.Equal(licenses, tt.wantLicenses) {
+				t.Errorf("parseSPDX(%q) licenses = %v, want %v", tt.expr, licenses, tt.wantLicenses)
+			}
+			if err = ValidateSPDX(tt.expr); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSPDX(%q) err = %v, want err %v", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	t.Parallel()
+
+	names := Templates()
+	if len(names) != len(licenseNameMap) {
+		t.Fatalf("Templates() len = %d, want %d", len(names), len(licenseNameMap))
+	}
+	if !slices.IsSorted(names) {
+		t.Errorf("Templates() = %v, want sorted", names)
+	}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl, ok := TemplateBySPDX(name)
+			if !ok {
+				t.Fatalf("TemplateBySPDX(%q) ok = false, want true", name)
+			}
+			h, err := NewHeader(HeaderOpts{Template: tmpl, Author: "Joshua Sing"})
+			if err != nil {
+				t.Fatalf("NewHeader err = %v", err)
+			}
+			header, err := h.Create("test.go")
+			if err != nil {
+				t.Fatalf("h.Create err = %v", err)
+			}
+			if !h.Matches(header) {
+				t.Errorf("h.Matches(%q) = false, want true", header)
+			}
+			if _, modified, err := h.Update("test.go", header); err != nil || modified {
+				t.Errorf("h.Update(%q) modified = %v, err = %v, want false, nil",
+					header, modified, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,3 @@
+// Copyright (c) 2019 Old Author
+
+package legacy
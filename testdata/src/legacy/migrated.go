@@ -0,0 +1,3 @@
+// Copyright (C) 2019 Test // want "legacy license header"
+
+package legacy
@@ -0,0 +1,3 @@
+// Copyright 2019 Test. All rights reserved. // want "legacy license header"
+
+package legacy
@@ -0,0 +1,4 @@
+// Copyright (c) 2019 Old Holder // want "license header requires migration"
+// Use of this source code is governed by an MIT license.
+
+package migration
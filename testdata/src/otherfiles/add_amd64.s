@@ -0,0 +1,2 @@
+TEXT ·add(SB), $0
+	RET
@@ -0,0 +1,3 @@
+// Copyright (c) 2001 Test
+
+#define ANSWER 42
@@ -0,0 +1,5 @@
+// Copyright (c) 2025 Test
+
+//go:build ignore
+
+package otherfiles
@@ -0,0 +1,3 @@
+// Copyright (c) 2025 Test
+
+package otherfiles
@@ -0,0 +1 @@
+package rules // want "missing license header"
@@ -0,0 +1,3 @@
+// Copyright (c) 2019 Test // want "cannot determine copyright year: history is truncated"
+
+package shallow
@@ -0,0 +1,3 @@
+// Copyright (c) 2018 Someone else // want "unrecognised license header"
+
+package strict
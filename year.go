@@ -0,0 +1,535 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golicenser
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// yearSource is a source of the start or end year in a year policy.
+type yearSource int
+
+const (
+	yearSourceNone yearSource = iota
+
+	// yearSourcePreserve uses the years in the existing license header. The
+	// earliest year is used as the start year, and the latest year is used as
+	// the end year.
+	yearSourcePreserve
+
+	// yearSourceNow uses the current year.
+	yearSourceNow
+
+	// yearSourceGitCreated uses the creation year from the file history.
+	yearSourceGitCreated
+
+	// yearSourceGitModified uses the last modified year from the file history.
+	yearSourceGitModified
+)
+
+var yearSourceStrings = map[yearSource]string{
+	yearSourcePreserve:    "preserve",
+	yearSourceNow:         "now",
+	yearSourceGitCreated:  "git-created",
+	yearSourceGitModified: "git-modified",
+}
+
+// yearFormat is a way of formatting the years in a year policy.
+type yearFormat int
+
+const (
+	// yearFormatRange formats the start and end year as a range, or a single
+	// year if they are the same, e.g. "2022-2025".
+	yearFormatRange yearFormat = iota
+
+	// yearFormatSingle formats the end year, e.g. "2025".
+	yearFormatSingle
+
+	// yearFormatList formats each year as a list, e.g. "2019, 2020, 2021, 2024".
+	yearFormatList
+
+	// yearFormatCompactList formats each year as a list, with consecutive years
+	// formatted as a range, e.g. "2019-2021, 2024".
+	yearFormatCompactList
+
+	// yearFormatPreserve uses the existing year verbatim, or the start and end
+	// year formatted as a range if there is no existing year.
+	yearFormatPreserve
+)
+
+var yearFormatStrings = map[yearFormat]string{
+	yearFormatRange:       "range",
+	yearFormatSingle:      "single",
+	yearFormatList:        "list",
+	yearFormatCompactList: "compact-list",
+	yearFormatPreserve:    "preserve",
+}
+
+// yearPolicy is a policy for calculating the copyright year(s) for a file.
+// The start and end year sources are tried in order, using the first source
+// that provides a year.
+type yearPolicy struct {
+	start  []yearSource
+	end    []yearSource
+	format yearFormat
+}
+
+// Year policies are encoded into a YearMode, so that year modes parsed from
+// expressions can be used in the same way as the named year modes. The
+// encoding sets yearModePolicy, followed by the start and end year sources
+// and the year format.
+const (
+	yearModePolicy YearMode = 1 << 30
+
+	yearSourceBits   = 3
+	maxYearSources   = 4
+	yearSourcesBits  = yearSourceBits * maxYearSources
+	yearSourceMask   = 1<<yearSourceBits - 1
+	yearFormatOffset = 2 * yearSourcesBits
+	yearFormatMask   = 1<<3 - 1
+
+	// yearModePolicyMask is the bits used by an encoded year policy.
+	yearModePolicyMask = yearModePolicy | yearFormatMask<<yearFormatOffset | (1<<yearFormatOffset - 1)
+)
+
+// yearModePolicies are the year policies used by the named year modes.
+var yearModePolicies = map[YearMode]yearPolicy{
+	YearModePreserve: {
+		format: yearFormatPreserve,
+	},
+	YearModePreserveThisYearRange: {
+		start:  []yearSource{yearSourcePreserve, yearSourceNow},
+		end:    []yearSource{yearSourceNow},
+		format: yearFormatRange,
+	},
+	YearModePreserveModifiedRange: {
+		start:  []yearSource{yearSourcePreserve},
+		end:    []yearSource{yearSourceGitModified, yearSourcePreserve},
+		format: yearFormatRange,
+	},
+	YearModeThisYear: {
+		end:    []yearSource{yearSourceNow},
+		format: yearFormatSingle,
+	},
+	YearModeLastModified: {
+		end:    []yearSource{yearSourceGitModified},
+		format: yearFormatSingle,
+	},
+	YearModeGitRange: {
+		start:  []yearSource{yearSourceGitCreated},
+		end:    []yearSource{yearSourceGitModified},
+		format: yearFormatRange,
+	},
+	YearModeGitModifiedList: {
+		start:  []yearSource{yearSourceGitCreated},
+		end:    []yearSource{yearSourceGitModified},
+		format: yearFormatList,
+	},
+}
+
+// parseYearPolicy parses a year policy expression. An expression is a list of
+// space-separated "key=value" pairs, where the key is "start", "end" or
+// "format". The start and end values are year sources separated by "|", which
+// are tried in order, e.g. "start=preserve|git-created end=git-modified
+// format=compact-list".
+//
+// If the start year is missing, the end year is used. If the end year is
+// missing, the current year is used. The default format is "range".
+func parseYearPolicy(s string) (yearPolicy, error) {
+	var p yearPolicy
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return p, errors.New("empty year policy")
+	}
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return p, fmt.Errorf("invalid year policy field %q: want key=value", field)
+		}
+		key = strings.ToLower(key)
+		if seen[key] {
+			return p, fmt.Errorf("duplicate year policy key %q", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "start":
+			p.start, err = parseYearSources(value)
+		case "end":
+			p.end, err = parseYearSources(value)
+		case "format":
+			p.format, err = parseYearFormat(value)
+		default:
+			return p, fmt.Errorf("unknown year policy key %q", key)
+		}
+		if err != nil {
+			return p, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return p, nil
+}
+
+// parseYearSources parses year sources separated by "|".
+func parseYearSources(s string) ([]yearSource, error) {
+	parts := strings.Split(s, "|")
+	if len(parts) > maxYearSources {
+		return nil, fmt.Errorf("too many year sources: %d (max %d)",
+			len(parts), maxYearSources)
+	}
+	sources := make([]yearSource, 0, len(parts))
+	for _, part := range parts {
+		source, ok := parseYearSource(part)
+		if !ok {
+			return nil, fmt.Errorf("invalid year source: %q", part)
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// parseYearSource parses a string representation of a year source.
+func parseYearSource(s string) (yearSource, bool) {
+	for source, str := range yearSourceStrings {
+		if strings.EqualFold(s, str) {
+			return source, true
+		}
+	}
+	return yearSourceNone, false
+}
+
+// parseYearFormat parses a string representation of a year format.
+func parseYearFormat(s string) (yearFormat, error) {
+	for f, str := range yearFormatStrings {
+		if strings.EqualFold(s, str) {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid year format: %q", s)
+}
+
+// String returns the canonical year policy expression.
+func (p yearPolicy) String() string {
+	var fields []string
+	if len(p.start) > 0 {
+		fields = append(fields, "start="+formatYearSources(p.start))
+	}
+	if len(p.end) > 0 {
+		fields = append(fields, "end="+formatYearSources(p.end))
+	}
+	fields = append(fields, "format="+yearFormatStrings[p.format])
+	return strings.Join(fields, " ")
+}
+
+// formatYearSources returns the year sources separated by "|".
+func formatYearSources(sources []yearSource) string {
+	s := make([]string, len(sources))
+	for i, source := range sources {
+		s[i] = yearSourceStrings[source]
+	}
+	return strings.Join(s, "|")
+}
+
+// encode encodes the year policy into a YearMode.
+func (p yearPolicy) encode() YearMode {
+	ym := yearModePolicy | YearMode(p.format)<<yearFormatOffset
+	for i, source := range p.start {
+		ym |= YearMode(source) << (i * yearSourceBits)
+	}
+	for i, source := range p.end {
+		ym |= YearMode(source) << (yearSourcesBits + i*yearSourceBits)
+	}
+	return ym
+}
+
+// mode returns the YearMode for the year policy. If the year policy is the
+// same as the policy of a named year mode, the named year mode is returned.
+func (p yearPolicy) mode() YearMode {
+	ym := p.encode()
+	for named, policy := range yearModePolicies {
+		if policy.encode() == ym {
+			return named
+		}
+	}
+	return ym
+}
+
+// policy returns the year policy for the year mode, and whether the year mode
+// is valid.
+func (ym YearMode) policy() (yearPolicy, bool) {
+	if p, ok := yearModePolicies[ym]; ok {
+		return p, true
+	}
+	if ym&yearModePolicy == 0 || ym&^yearModePolicyMask != 0 {
+		return yearPolicy{}, false
+	}
+
+	p := yearPolicy{format: yearFormat(ym >> yearFormatOffset & yearFormatMask)}
+	if _, ok := yearFormatStrings[p.format]; !ok {
+		return yearPolicy{}, false
+	}
+	var ok bool
+	if p.start, ok = decodeYearSources(ym); !ok {
+		return yearPolicy{}, false
+	}
+	if p.end, ok = decodeYearSources(ym >> yearSourcesBits); !ok {
+		return yearPolicy{}, false
+	}
+	return p, true
+}
+
+// decodeYearSources decodes the year sources in the lowest bits of the
+// YearMode.
+func decodeYearSources(ym YearMode) ([]yearSource, bool) {
+	var sources []yearSource
+	for i := range maxYearSources {
+		source := yearSource(ym >> (i * yearSourceBits) & yearSourceMask)
+		if source == yearSourceNone {
+			break
+		}
+		if _, ok := yearSourceStrings[source]; !ok {
+			return nil, false
+		}
+		sources = append(sources, source)
+	}
+	return sources, true
+}
+
+// usesHistory returns whether the year policy uses the file history.
+func (p yearPolicy) usesHistory() bool {
+	for _, source := range slices.Concat(p.start, p.end) {
+		if source == yearSourceGitCreated || source == yearSourceGitModified {
+			return true
+		}
+	}
+	return false
+}
+
+// yearRange is a year or range of years in a license header.
+type yearRange struct {
+	start, end int
+
+	// present is whether the range ends at the present year, e.g.
+	// "2016-present".
+	present bool
+}
+
+// yearSet is the years and year ranges in a license header, in the order they
+// are written, e.g. "2012-2014, 2018".
+type yearSet []yearRange
+
+// parseYearSet parses the years and year ranges in s. Years are separated by
+// commas, and ranges may be separated by hyphens or dashes with optional
+// spaces, e.g. "2015 - 2019", "2015–2019", "2012-2014, 2018", "2016-present"
+// and "2016,2018".
+func parseYearSet(s string) yearSet {
+	var ys yearSet
+	for _, m := range regexpYearRange.FindAllStringSubmatch(s, -1) {
+		start, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue
+		}
+		r := yearRange{start: start, end: start}
+		switch {
+		case m[2] == "":
+		case strings.EqualFold(m[2], "present"):
+			r.present = true
+		default:
+			if r.end, err = strconv.Atoi(m[2]); err != nil {
+				continue
+			}
+		}
+		ys = append(ys, r)
+	}
+	return ys
+}
+
+// years returns the sorted years in the year set, including each year within
+// a year range. Ranges ending at the present year end at the current year.
+func (ys yearSet) years(current int) []int {
+	var years []int
+	for _, r := range ys {
+		start, end := r.start, r.end
+		if r.present {
+			end = max(start, current)
+		}
+		if end < start {
+			start, end = end, start
+		}
+		for y := start; y <= end; y++ {
+			years = append(years, y)
+		}
+	}
+	slices.Sort(years)
+	return slices.Compact(years)
+}
+
+// year returns the copyright year(s) for the file using the year mode. The
+// existing year is the year in the existing license header, if any. An error
+// is only returned if the history is truncated and the shallow policy is
+// ShallowFail.
+func (h *Header) year(filename, existing string) (string, error) {
+	p, _ := h.yearMode.policy()
+	ys := parseYearSet(existing)
+	if p.format == yearFormatPreserve && existing != "" {
+		return existing, nil
+	}
+
+	existingYears := ys.years(h.in(h.now()).Year())
+	start, startOK, err := h.sourceYear(p.start, filename, existingYears, true)
+	if err != nil {
+		return "", err
+	}
+	end, endOK, err := h.sourceYear(p.end, filename, existingYears, false)
+	if err != nil {
+		return "", err
+	}
+	if !endOK {
+		end = h.in(h.now()).Year()
+	}
+	if !startOK {
+		start = end
+	}
+	if end < start {
+		end = start
+	}
+
+	switch p.format {
+	case yearFormatSingle:
+		return strconv.Itoa(end), nil
+	case yearFormatList, yearFormatCompactList:
+		years, err := h.listYears(p, filename, existingYears, start, end)
+		if err != nil {
+			return "", err
+		}
+		return formatYearList(years, p.format == yearFormatCompactList), nil
+	default:
+		return formatYearRange(start, end), nil
+	}
+}
+
+// sourceYear returns the year from the first year source that provides a
+// year. The start year is used to choose between the earliest and latest
+// existing year.
+func (h *Header) sourceYear(sources []yearSource, filename string, existingYears []int, start bool) (int, bool, error) {
+	for _, source := range sources {
+		switch source {
+		case yearSourcePreserve:
+			if len(existingYears) == 0 {
+				continue
+			}
+			if start {
+				return existingYears[0], true, nil
+			}
+			return existingYears[len(existingYears)-1], true, nil
+		case yearSourceNow:
+			return h.in(h.now()).Year(), true, nil
+		case yearSourceGitCreated:
+			created, err := h.history.Created(filename)
+			if err != nil && !errors.Is(err, ErrHistoryTruncated) {
+				continue
+			}
+			year := h.in(created).Year()
+			if err != nil {
+				// History is truncated, so the creation year is unknown.
+				if year, err = h.truncatedStart(year, existingYears, err); err != nil {
+					return 0, false, err
+				}
+			}
+			return year, true, nil
+		case yearSourceGitModified:
+			if modified, err := h.history.Modified(filename); err == nil {
+				return h.in(modified).Year(), true, nil
+			}
+		}
+	}
+	return 0, false, nil
+}
+
+// listYears returns the sorted years between the start and end year to list
+// for the file. If the year policy uses the file history, each year the file
+// was modified is included, and if the policy preserves the existing years,
+// the existing years are included.
+func (h *Header) listYears(p yearPolicy, filename string, existingYears []int, start, end int) ([]int, error) {
+	years := []int{start, end}
+	if slices.Contains(p.start, yearSourcePreserve) || slices.Contains(p.end, yearSourcePreserve) {
+		years = append(years, existingYears...)
+	}
+	if p.usesHistory() {
+		modTimes, err := h.history.ModTimes(filename)
+		if err == nil || errors.Is(err, ErrHistoryTruncated) {
+			modYears := make([]int, 0, len(modTimes))
+			for _, modTime := range modTimes {
+				modYears = append(modYears, h.in(modTime).Year())
+			}
+			if err != nil {
+				// History is truncated, so years before the visible history
+				// are unknown.
+				if modYears, err = h.truncatedYears(modYears, existingYears, err); err != nil {
+					return nil, err
+				}
+			}
+			years = append(years, modYears...)
+		}
+		if dirty, err := h.history.IsDirty(filename); err == nil && dirty {
+			// File has changed locally, add local modification time.
+			if modTime, err := h.history.Modified(filename); err == nil {
+				years = append(years, h.in(modTime).Year())
+			}
+		}
+	}
+	years = slices.DeleteFunc(years, func(y int) bool {
+		return y < start || y > end
+	})
+	slices.Sort(years)
+	return slices.Compact(years), nil
+}
+
+// formatYearRange formats a year range, or a single year if the start and end
+// year are the same.
+func formatYearRange(start, end int) string {
+	if start == end {
+		return strconv.Itoa(start)
+	}
+	return strconv.Itoa(start) + "-" + strconv.Itoa(end)
+}
+
+// formatYearList formats sorted years as a comma-separated list. If compact is
+// true, consecutive years are formatted as a range.
+func formatYearList(years []int, compact bool) string {
+	var parts []string
+	for i := 0; i < len(years); i++ {
+		j := i
+		if compact {
+			for j+1 < len(years) && years[j+1] == years[j]+1 {
+				j++
+			}
+		}
+		parts = append(parts, formatYearRange(years[i], years[j]))
+		i = j
+	}
+	return strings.Join(parts, ", ")
+}
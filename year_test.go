@@ -0,0 +1,302 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golicenser
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseYearModePolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		s       string
+		want    string
+		wantErr bool
+	}{
+		{
+			s:    "start=preserve|git-created end=git-modified format=compact-list",
+			want: "start=preserve|git-created end=git-modified format=compact-list",
+		},
+		{
+			s:    "END=Now  Format=Range",
+			want: "end=now format=range",
+		},
+		{
+			s:    "start=git-created end=git-modified",
+			want: "git-range",
+		},
+		{
+			s:    "end=now format=single",
+			want: "this-year",
+		},
+		{
+			s:    "format=preserve",
+			want: "preserve",
+		},
+		{s: "start=", wantErr: true},
+		{s: "start=sometimes", wantErr: true},
+		{s: "start=now|now|now|now|now", wantErr: true},
+		{s: "middle=now", wantErr: true},
+		{s: "format=roman", wantErr: true},
+		{s: "end=now end=now", wantErr: true},
+		{s: "end=now format", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseYearMode(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseYearMode(%q) err = %v, want err %v", tt.s, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseYearMode(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+
+			// The string representation must parse to the same year mode.
+			text, err := got.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText() err = %v", err)
+			}
+			var ym YearMode
+			if err = ym.UnmarshalText(text); err != nil {
+				t.Fatalf("UnmarshalText(%q) err = %v", text, err)
+			}
+			if ym != got {
+				t.Errorf("UnmarshalText(%q) = %v, want %v", text, ym, got)
+			}
+		})
+	}
+}
+
+func TestYearModeInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, ym := range []YearMode{-1, 100, yearModePolicy | 7, yearModePolicy | 7<<yearFormatOffset} {
+		if s := ym.String(); s != "" {
+			t.Errorf("YearMode(%d).String() = %q, want empty", ym, s)
+		}
+		if _, err := ym.MarshalText(); err == nil {
+			t.Errorf("YearMode(%d).MarshalText() err = nil, want error", ym)
+		}
+	}
+}
+
+func TestParseYearSet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		s         string
+		wantYears []int
+	}{
+		{s: "2025", wantYears: []int{2025}},
+		{s: "2022-2025", wantYears: []int{2022, 2023, 2024, 2025}},
+		{s: "2015 - 2019", wantYears: []int{2015, 2016, 2017, 2018, 2019}},
+		{s: "2015–2019", wantYears: []int{2015, 2016, 2017, 2018, 2019}},
+		{s: "2012-2014, 2018", wantYears: []int{2012, 2013, 2014, 2018}},
+		{s: "2016-present", wantYears: []int{2016, 2017, 2018}},
+		{s: "2016-Present", wantYears: []int{2016, 2017, 2018}},
+		{s: "2016,2018", wantYears: []int{2016, 2018}},
+		{s: "2018, 2016, 2018", wantYears: []int{2016, 2018}},
+		{s: "2016 by Someone", wantYears: []int{2016}},
+		{s: ""},
+	}
+	for _, tt := range tests {
+		if got := parseYearSet(tt.s).years(2018); !slices.Equal(got, tt.wantYears) {
+			t.Errorf("parseYearSet(%q).years() = %v, want %v", tt.s, got, tt.wantYears)
+		}
+	}
+}
+
+func TestFormatYearList(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		years       []int
+		wantList    string
+		wantCompact string
+	}{
+		{years: []int{2025}, wantList: "2025", wantCompact: "2025"},
+		{years: []int{2024, 2025}, wantList: "2024, 2025", wantCompact: "2024-2025"},
+		{
+			years:       []int{2019, 2020, 2021, 2024},
+			wantList:    "2019, 2020, 2021, 2024",
+			wantCompact: "2019-2021, 2024",
+		},
+		{
+			years:       []int{2016, 2018, 2019, 2022, 2023, 2024},
+			wantList:    "2016, 2018, 2019, 2022, 2023, 2024",
+			wantCompact: "2016, 2018-2019, 2022-2024",
+		},
+	}
+	for _, tt := range tests {
+		if got := formatYearList(tt.years, false); got != tt.wantList {
+			t.Errorf("formatYearList(%v, false) = %q, want %q", tt.years, got, tt.wantList)
+		}
+		if got := formatYearList(tt.years, true); got != tt.wantCompact {
+			t.Errorf("formatYearList(%v, true) = %q, want %q", tt.years, got, tt.wantCompact)
+		}
+	}
+}
+
+func TestHeaderYearPolicy(t *testing.T) {
+	t.Parallel()
+
+	history := testHistory{
+		created:  yearTime(2019),
+		modified: yearTime(2024),
+		modTimes: []time.Time{yearTime(2019), yearTime(2020), yearTime(2021), yearTime(2024)},
+	}
+	tests := []struct {
+		name     string
+		policy   string
+		history  History
+		existing string
+
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "compact list",
+			policy:  "start=preserve|git-created end=git-modified format=compact-list",
+			history: history,
+			want:    "2019-2021, 2024",
+		},
+		{
+			name:     "compact list from existing year",
+			policy:   "start=preserve|git-created end=git-modified format=compact-list",
+			history:  history,
+			existing: "2021",
+			want:     "2021, 2024",
+		},
+		{
+			name:     "compact list with earlier existing year",
+			policy:   "start=preserve|git-created end=git-modified format=compact-list",
+			history:  history,
+			existing: "2016",
+			want:     "2016, 2019-2021, 2024",
+		},
+		{
+			name:    "full list",
+			policy:  "start=git-created end=git-modified format=list",
+			history: history,
+			want:    "2019, 2020, 2021, 2024",
+		},
+		{
+			name:    "list without history",
+			policy:  "start=now end=now format=list",
+			history: history,
+			want:    "2025",
+		},
+		{
+			name:     "range from existing to now",
+			policy:   "start=preserve end=now",
+			history:  history,
+			existing: "2020-2022",
+			want:     "2020-2025",
+		},
+		{
+			name:     "range from existing to modified",
+			policy:   "start=preserve|git-created end=git-modified|now format=range",
+			history:  history,
+			existing: "2021",
+			want:     "2021-2024",
+		},
+		{
+			name:    "missing start uses end",
+			policy:  "start=preserve end=git-modified",
+			history: history,
+			want:    "2024",
+		},
+		{
+			name:    "missing end uses now",
+			policy:  "start=git-created end=preserve",
+			history: history,
+			want:    "2019-2025",
+		},
+		{
+			name:     "single",
+			policy:   "start=git-created end=git-modified format=single",
+			history:  history,
+			existing: "2001",
+			want:     "2024",
+		},
+		{
+			name:     "preserve format",
+			policy:   "start=git-created end=git-modified format=preserve",
+			history:  history,
+			existing: "2001, 2003",
+			want:     "2001, 2003",
+		},
+		{
+			name:    "preserve format without existing year",
+			policy:  "start=git-created end=git-modified format=preserve",
+			history: history,
+			want:    "2019-2024",
+		},
+		{
+			name:   "truncated history",
+			policy: "start=git-created end=git-modified format=compact-list",
+			history: testHistory{
+				created:   yearTime(2023),
+				modified:  yearTime(2024),
+				modTimes:  []time.Time{yearTime(2023), yearTime(2024)},
+				truncated: true,
+			},
+			existing: "2018, 2020",
+			want:     "2018, 2020, 2023-2024",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ym, err := ParseYearMode(tt.policy)
+			if err != nil {
+				t.Fatalf("ParseYearMode(%q) err = %v", tt.policy, err)
+			}
+			h, err := NewHeader(HeaderOpts{
+				Template: "{{.year}}",
+				Author:   "Joshua Sing",
+				YearMode: ym,
+				History:  tt.history,
+			})
+			if err != nil {
+				t.Fatalf("NewHeader() err = %v", err)
+			}
+			got, err := h.year("test.go", tt.existing)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("h.year() err = %v, want err %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrHistoryTruncated) {
+				t.Errorf("h.year() err = %v, want ErrHistoryTruncated", err)
+			}
+			if got != tt.want {
+				t.Errorf("h.year() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}